@@ -0,0 +1,203 @@
+package boot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/why2go/gostarter/config"
+)
+
+// 管理端口，与gin使用不同的端口，提供如下接口：
+//
+//	/healthz  存活状态
+//	/readyz   就绪状态，包含各项健康检查的结果
+//	/info     应用信息和构建信息
+//	/config   脱敏后的配置内容
+//
+// 配置如下：
+//
+//	admin:
+//	  enabled: true
+//	  host: ""
+//	  port: 8090
+//	  checkTimeout: 3s # 健康检查的时限，默认3s
+
+const (
+	adminComponentName = "boot:admin"
+	redactedValue      = "******"
+)
+
+var (
+	defaultAdminPort         = uint16(8090)
+	defaultAdminCheckTimeout = 3 * time.Second
+
+	// 名字匹配的配置项在/config中会被隐藏，宁可多隐藏也不要泄露，可以通过admin.redactKeys追加
+	sensitiveKeyPattern = `password|passwd|pwd|secret|token|credential|private|dsn|conn_?url|connection_?string|` +
+		`key$|api_?key|access_?key|auth(?:$|[^o])|authorization|jwt|cert|signature|salt|session|cookie`
+	sensitiveKeyRegexp = regexp.MustCompile(`(?i)(` + sensitiveKeyPattern + `)`)
+)
+
+type adminConf struct {
+	Enabled      bool            `yaml:"enabled" json:"enabled"`
+	Host         string          `yaml:"host" json:"host"`
+	Port         uint16          `yaml:"port" json:"port"`
+	CheckTimeout config.Duration `yaml:"checkTimeout" json:"checkTimeout"`
+	RedactKeys   []string        `yaml:"redactKeys" json:"redactKeys"` // 额外需要在/config中隐藏的配置项名，包含即匹配，不区分大小写
+}
+
+func (adminConf) ConfigName() string {
+	return "admin"
+}
+
+type adminServer struct {
+	app          *App
+	addr         string
+	checkTimeout time.Duration
+	redactRegexp *regexp.Regexp
+	server       *http.Server
+}
+
+func newAdminServer(app *App, cfg *adminConf) (*adminServer, error) {
+	srv := &adminServer{
+		app:          app,
+		checkTimeout: defaultAdminCheckTimeout,
+		redactRegexp: sensitiveKeyRegexp,
+	}
+	if len(cfg.RedactKeys) != 0 {
+		pattern := sensitiveKeyPattern
+		for _, k := range cfg.RedactKeys {
+			if k = strings.TrimSpace(k); len(k) != 0 {
+				pattern += "|" + regexp.QuoteMeta(k)
+			}
+		}
+		srv.redactRegexp = regexp.MustCompile(`(?i)(` + pattern + `)`)
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = defaultAdminPort
+	}
+	srv.addr = fmt.Sprintf("%s:%d", cfg.Host, port)
+	if cfg.CheckTimeout > 0 {
+		srv.checkTimeout = cfg.CheckTimeout.Std()
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", srv.handleHealthz)
+	mux.HandleFunc("/readyz", srv.handleReadyz)
+	mux.HandleFunc("/info", srv.handleInfo)
+	mux.HandleFunc("/config", srv.handleConfig)
+	srv.server = &http.Server{Handler: mux}
+	return srv, nil
+}
+
+func (srv *adminServer) Start(ctx context.Context) error {
+	lis, err := net.Listen("tcp", srv.addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := srv.server.Serve(lis); err != nil && err != http.ErrServerClosed {
+			logger.Err(err).Msg("admin server serve failed")
+		}
+	}()
+	logger.Info().Msgf("admin server is listening on %s", lis.Addr())
+	return nil
+}
+
+func (srv *adminServer) Stop(ctx context.Context) error {
+	return srv.server.Shutdown(ctx)
+}
+
+func (srv *adminServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, srv.app.Liveness())
+}
+
+func (srv *adminServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cf := context.WithTimeout(r.Context(), srv.checkTimeout)
+	defer cf()
+	writeHealthReport(w, srv.app.Readiness(ctx))
+}
+
+func (srv *adminServer) handleInfo(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, srv.app.BuildInfo())
+}
+
+func (srv *adminServer) handleConfig(w http.ResponseWriter, r *http.Request) {
+	settings, err := config.AllSettings()
+	if err != nil {
+		writeJson(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJson(w, http.StatusOK, redact(settings, srv.redactRegexp))
+}
+
+func writeHealthReport(w http.ResponseWriter, report *HealthReport) {
+	code := http.StatusOK
+	if report.Status != StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	writeJson(w, code, report)
+}
+
+func writeJson(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Err(err).Msg("write admin response failed")
+	}
+}
+
+// 隐藏敏感的配置项，以及url中的密码
+func redact(v interface{}, sensitive *regexp.Regexp) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if sensitive.MatchString(k) {
+				m[k] = redactedValue
+			} else {
+				m[k] = redact(item, sensitive)
+			}
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(val))
+		for i := range val {
+			l[i] = redact(val[i], sensitive)
+		}
+		return l
+	case string:
+		if u, err := url.Parse(val); err == nil && u.User != nil {
+			return u.Redacted()
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+// 读取admin配置项，启用时将管理端口注册为应用的组件
+func (app *App) setupAdmin(source func(config.Configurable) error) error {
+	cfg := &adminConf{}
+	err := source(cfg)
+	if err != nil {
+		if isMissingConfig(err) {
+			return nil
+		}
+		return err
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	srv, err := newAdminServer(app, cfg)
+	if err != nil {
+		return err
+	}
+	return app.AddComponent(adminComponentName, srv)
+}
@@ -2,11 +2,11 @@ package boot
 
 import (
 	"context"
-
+	"errors"
 	"fmt"
-	"os/signal"
-	"reflect"
-	"syscall"
+	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/why2go/gostarter/config"
 	_ "github.com/why2go/gostarter/zerologstarter"
@@ -19,18 +19,19 @@ import (
 
 var (
 	logger      zerolog.Logger
-	appInstance *app
+	appInstance *App // 包级函数使用的默认应用实例
 )
 
 func init() {
 	logger = log.With().Str("ltag", "boot").Logger()
-	appConfig := &appConf{}
-	err := config.GetConfig(appConfig)
+	app, err := New()
 	if err != nil {
-		logger.Fatal().Err(err).Msg("load app config failed")
-		return
+		// 使用默认配置，错误由Start返回
+		logger.Error().Err(err).Msg("load app config failed")
+		app, _ = newApp(&appConf{})
+		app.recordErr(err)
 	}
-	appInstance = newApp(appConfig)
+	appInstance = app
 }
 
 type appConf struct {
@@ -39,127 +40,396 @@ type appConf struct {
 	Version     string `yaml:"version" json:"version"`
 	ChangeLog   string `yaml:"changeLog" json:"changeLog"`
 	Description string `yaml:"description" json:"description"`
+	// 启动、清理的整体时限，以及单个启动/清理函数的默认时限，为0表示不限制
+	StartupTimeout  config.Duration `yaml:"startupTimeout" json:"startupTimeout"`
+	ShutdownTimeout config.Duration `yaml:"shutdownTimeout" json:"shutdownTimeout" default:"5m"`
+	HookTimeout     config.Duration `yaml:"hookTimeout" json:"hookTimeout"`
+	// 为指定的启动/清理函数单独设置时限，key为组件名或者函数全名
+	HookTimeouts map[string]config.Duration `yaml:"hookTimeouts" json:"hookTimeouts"`
+	// 开始清理时，就绪状态变为DOWN，等待负载均衡摘除流量之后再执行清理函数，默认不等待
+	DrainDelay config.Duration `yaml:"drainDelay" json:"drainDelay"`
+	// 触发清理的信号，默认为SIGINT和SIGTERM
+	Signals []string `yaml:"signals" json:"signals"`
+	// 触发重新加载的信号，默认为SIGHUP
+	ReloadSignals []string `yaml:"reloadSignals" json:"reloadSignals"`
 }
 
 func (appConf) ConfigName() string {
 	return "app"
 }
 
-type app struct {
-	name        string
-	author      string
-	version     string
-	changeLog   string
-	description string
-	starters    []interface{}
-	sweepers    []interface{}
+// 一个应用实例，管理组件的启动和清理。
+// 一般直接使用包级函数操作默认实例即可，需要在测试中独立启停应用时可以通过New创建
+type App struct {
+	name            string
+	author          string
+	version         string
+	changeLog       string
+	description     string
+	startupTimeout  time.Duration
+	shutdownTimeout time.Duration
+	hookTimeout     time.Duration
+	hookTimeouts    map[string]time.Duration
+	drainDelay      time.Duration
+	signals         []os.Signal
+	reloadSignals   []os.Signal
+	starters        []*hook
+	sweepers        []*hook
+	reloadHooks     []*hook
+	components      map[string]*component // 具名组件
+	started         []*stage              // 按启动顺序排列的阶段，只包含启动成功的组件
+	startedStarters int                   // 启动成功的AddStarters函数个数
+	state           atomic.Int32          // 应用所处的生命周期阶段
+	health          healthRegistry
+	initErrs        []error // 通过包级函数注册时出现的错误，由Start返回
+}
+
+type Option func(*appOptions)
+
+type appOptions struct {
+	name         string
+	version      string
+	configSource func(config.Configurable) error
 }
 
-func newApp(cfg *appConf) *app {
-	app := &app{
-		name:        cfg.AppName,
-		author:      cfg.Author,
-		version:     cfg.Version,
-		changeLog:   cfg.ChangeLog,
-		description: cfg.Description,
+// 设置应用名称，优先于配置文件中的name
+func WithName(name string) Option {
+	return func(o *appOptions) {
+		o.name = name
 	}
-	return app
 }
 
-// staters shall be "func()" or "func() error"
-func AddStarters(starters ...interface{}) {
+// 设置应用版本，优先于配置文件中的version
+func WithVersion(version string) Option {
+	return func(o *appOptions) {
+		o.version = version
+	}
+}
+
+// 设置读取app配置项的方式，默认使用config.GetConfig。
+// 其他来源需要自行设置默认值，例如调用config.SetDefaults
+func WithConfigSource(source func(config.Configurable) error) Option {
+	return func(o *appOptions) {
+		o.configSource = source
+	}
+}
+
+// 创建一个应用实例，没有找到app配置项时使用默认配置
+func New(opts ...Option) (*App, error) {
+	o := &appOptions{configSource: config.GetConfig}
+	for _, opt := range opts {
+		opt(o)
+	}
+	cfg := &appConf{}
+	err := o.configSource(cfg)
+	if err != nil && !isMissingConfig(err) {
+		return nil, err
+	}
+	app, err := newApp(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if o.name != "" {
+		app.name = o.name
+	}
+	if meta := readBuildMeta(); meta.version != "" && (buildVersion != "" || app.version == "") {
+		app.version = meta.version
+	}
+	if o.version != "" {
+		app.version = o.version
+	}
+	if err := app.setupAdmin(o.configSource); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func newApp(cfg *appConf) (*App, error) {
+	app := &App{
+		name:            cfg.AppName,
+		author:          cfg.Author,
+		version:         cfg.Version,
+		changeLog:       cfg.ChangeLog,
+		description:     cfg.Description,
+		startupTimeout:  cfg.StartupTimeout.Std(),
+		shutdownTimeout: cfg.ShutdownTimeout.Std(),
+		hookTimeout:     cfg.HookTimeout.Std(),
+		hookTimeouts:    make(map[string]time.Duration),
+		drainDelay:      cfg.DrainDelay.Std(),
+		components:      make(map[string]*component),
+		health:          healthRegistry{checkers: make(map[string]HealthChecker)},
+		signals:         defaultSignals,
+		reloadSignals:   defaultReloadSignals,
+	}
+	var err error
+	if len(cfg.Signals) != 0 {
+		if app.signals, err = parseSignals(cfg.Signals); err != nil {
+			return nil, fmt.Errorf("invalid signals: %w", err)
+		}
+	}
+	if len(cfg.ReloadSignals) != 0 {
+		if app.reloadSignals, err = parseSignals(cfg.ReloadSignals); err != nil {
+			return nil, fmt.Errorf("invalid reloadSignals: %w", err)
+		}
+	}
+	for name, d := range cfg.HookTimeouts {
+		app.hookTimeouts[name] = d.Std()
+	}
+	return app, nil
+}
+
+// 没有配置文件或者配置项时使用默认配置
+func isMissingConfig(err error) bool {
+	return errors.Is(err, config.ErrNoConfigItemFound) || errors.Is(err, config.ErrNoConfigFileFound)
+}
+
+func (app *App) recordErr(err error) {
+	if err != nil {
+		logger.Error().Err(err).Send()
+		app.initErrs = append(app.initErrs, err)
+	}
+}
+
+func (app *App) timeoutOf(h *hook) time.Duration {
+	if d, ok := app.hookTimeouts[h.name]; ok {
+		return d
+	}
+	return app.hookTimeout
+}
+
+// staters shall be "func()", "func() error", "func(context.Context) error" or Lifecycle
+func (app *App) AddStarters(starters ...interface{}) error {
 	for _, f := range starters {
-		switch f.(type) {
-		case func():
-		case func() error:
-		default:
-			err := fmt.Errorf("invalid starter: %s", reflect.TypeOf(f))
-			logger.Fatal().Err(err).Send()
+		h, err := newHook(f, false)
+		if err != nil {
+			return fmt.Errorf("invalid starter: %w", err)
 		}
+		app.starters = append(app.starters, h)
 	}
-	appInstance.starters = append(appInstance.starters, starters...)
+	return nil
 }
 
-func AddSweepers(sweepers ...interface{}) {
+// sweepers shall be "func()", "func() error", "func(context.Context) error" or Lifecycle
+func (app *App) AddSweepers(sweepers ...interface{}) error {
 	for _, f := range sweepers {
-		switch f.(type) {
-		case func():
-		case func() error:
-		default:
-			err := fmt.Errorf("invalid sweeper: %s", reflect.TypeOf(f))
-			logger.Fatal().Err(err).Send()
+		h, err := newHook(f, true)
+		if err != nil {
+			return fmt.Errorf("invalid sweeper: %w", err)
 		}
+		app.sweepers = append(app.sweepers, h)
 	}
-	appInstance.sweepers = append(appInstance.sweepers, sweepers...)
+	return nil
 }
 
-func Run() {
-	startup()
-	shutdown()
+// 启动应用，然后等待ctx结束或者收到退出信号，最后清理应用。
+// 清理过程中再次收到退出信号会强制退出进程。返回启动或者清理过程中出现的错误
+func (app *App) Run(ctx context.Context) error {
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+
+	stop := app.waitForSignal(ctx)
+	defer stop()
+
+	return app.Stop(context.Background())
 }
 
-func GetAppName() string {
-	return appInstance.name
+func (app *App) Name() string {
+	return app.name
 }
 
-func GetAppVersion() string {
-	return appInstance.version
+func (app *App) Version() string {
+	return app.version
 }
 
-func GetAppDescription() string {
-	return appInstance.description
+func (app *App) Description() string {
+	return app.description
 }
 
-func GetAppAuthor() string {
-	return appInstance.author
+func (app *App) Author() string {
+	return app.author
 }
 
-func GetAppChangeLog() string {
-	return appInstance.changeLog
+func (app *App) ChangeLog() string {
+	return app.changeLog
+}
+
+func withOptionalTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d > 0 {
+		return context.WithTimeout(parent, d)
+	}
+	return context.WithCancel(parent)
 }
 
-func startup() {
-	logger.Info().Msgf("[%s] is starting", GetAppName())
+// 先按依赖顺序启动具名组件，再按注册顺序执行通过AddStarters添加的启动函数。
+// 同一阶段的组件并发启动，阶段内的错误汇总后才会进入回滚。
+// 任意启动函数失败时，按逆序清理已经成功启动的具名组件，并返回汇总后的错误。
+// 通过AddStarters和AddSweepers添加的函数按注册顺序一一对应，回滚时逆序执行已成功的启动函数对应的清理函数。
+// 通过包级函数注册时出现过错误则不会启动任何组件，直接返回这些错误
+func (app *App) Start(ctx context.Context) error {
+	if len(app.initErrs) != 0 {
+		return fmt.Errorf("app initialization failed: %w", errors.Join(app.initErrs...))
+	}
+	logger.Info().Msgf("[%s] is starting", app.name)
+	app.state.Store(stateStarting)
+	app.logBuildInfo()
+
+	ctx, cf := withOptionalTimeout(ctx, app.startupTimeout)
+	defer cf()
 
-	for _, f := range appInstance.starters {
-		switch v := f.(type) {
-		case func():
-			v()
-		case func() error:
-			err := v()
-			if err != nil {
-				logger.Fatal().Err(err).Send()
+	stages, err := planStages(app.components)
+	if err != nil {
+		return fmt.Errorf("resolve component dependencies failed: %w", err)
+	}
+	for _, s := range stages {
+		if len(s.components) > 1 {
+			logger.Info().Msgf("starting stage: %s", s)
+		}
+		started, err := s.forEach(func(c *component) error {
+			logger.Info().Msgf("starting component: %s", c.name)
+			if err := runHook(ctx, c.starter, app.timeoutOf(c.starter)); err != nil {
+				return fmt.Errorf("start component %s failed: %w", c.name, err)
+			}
+			if c.reporter != nil {
+				app.health.setComponent(c.name, c.reporter.HealthCheckers())
 			}
-		default:
-			err := fmt.Errorf("invalid starter: %s", reflect.TypeOf(f))
-			logger.Fatal().Err(err).Send()
+			return nil
+		})
+		if len(started) != 0 {
+			app.started = append(app.started, &stage{name: s.name, components: started})
+		}
+		if err != nil {
+			return app.rollback(err)
+		}
+	}
+
+	for _, h := range app.starters {
+		if err := runHook(ctx, h, app.timeoutOf(h)); err != nil {
+			return app.rollback(fmt.Errorf("starter %s failed: %w", h.name, err))
+		}
+		app.startedStarters++
+	}
+
+	app.state.Store(stateRunning)
+	logger.Info().Msgf("successfully start [%s]!", app.name)
+	return nil
+}
+
+// 启动失败时，先逆序执行已成功的启动函数对应的清理函数，再清理已经启动的具名组件
+func (app *App) rollback(cause error) error {
+	logger.Error().Err(cause).Msgf("[%s] failed to start, rolling back started components", app.name)
+	ctx, cf := withOptionalTimeout(context.Background(), app.shutdownTimeout)
+	defer cf()
+	errs := []error{cause}
+	for i := app.startedStarters - 1; i >= 0; i-- {
+		if i >= len(app.sweepers) {
+			continue
+		}
+		h := app.sweepers[i]
+		if err := runHook(ctx, h, app.timeoutOf(h)); err != nil {
+			logger.Error().Err(err).Msg("app sweep error") // don't panic, go on sweeping
+			errs = append(errs, fmt.Errorf("sweeper %s failed: %w", h.name, err))
 		}
 	}
+	app.startedStarters = 0
+	errs = append(errs, app.stopComponents(ctx)...)
+	app.state.Store(stateStopped)
+	return errors.Join(errs...)
+}
 
-	logger.Info().Msgf("successfully start [%s]!", GetAppName())
+// 按启动的逆序清理具名组件，同一阶段的组件并发清理，清理失败不会中断后续的清理
+func (app *App) stopComponents(ctx context.Context) []error {
+	var errs []error
+	for i := len(app.started) - 1; i >= 0; i-- {
+		_, err := app.started[i].forEach(func(c *component) error {
+			app.health.removeComponent(c.name)
+			if c.sweeper == nil {
+				return nil
+			}
+			logger.Info().Msgf("stopping component: %s", c.name)
+			if err := runHook(ctx, c.sweeper, app.timeoutOf(c.sweeper)); err != nil {
+				logger.Error().Err(err).Msgf("stop component %s failed", c.name) // don't panic, go on sweeping
+				return fmt.Errorf("stop component %s failed: %w", c.name, err)
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	app.started = nil
+	return errs
 }
 
-func shutdown() {
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGSTOP, syscall.SIGINT, syscall.SIGTERM)
+// 先将就绪状态置为DOWN并等待drainDelay，然后按注册顺序执行通过AddSweepers添加的清理函数，
+// 再按启动的逆序清理具名组件。返回汇总后的清理错误
+func (app *App) Stop(ctx context.Context) error {
+	logger.Info().Msgf("[%s] is sweeping", app.name)
+	app.state.Store(stateStopping)
 
-	<-ctx.Done()
-	stop()
+	ctx, cf := withOptionalTimeout(ctx, app.shutdownTimeout)
+	defer cf()
 
-	logger.Info().Msgf("[%s] is sweeping", GetAppName())
+	if app.drainDelay > 0 {
+		logger.Info().Msgf("waiting %s for traffic to drain", app.drainDelay)
+		select {
+		case <-time.After(app.drainDelay):
+		case <-ctx.Done():
+		}
+	}
 
-	for _, f := range appInstance.sweepers {
-		switch v := f.(type) {
-		case func():
-			v()
-		case func() error:
-			err := v()
+	var errs []error
+	for _, h := range app.sweepers {
+		if err := runHook(ctx, h, app.timeoutOf(h)); err != nil {
 			logger.Error().Err(err).Msg("app sweep error") // don't panic, go on sweeping
-		default:
-			err := fmt.Errorf("invalid sweeper: %s", reflect.TypeOf(f))
-			logger.Fatal().Err(err).Send()
+			errs = append(errs, fmt.Errorf("sweeper %s failed: %w", h.name, err))
 		}
 	}
 
-	fmt.Printf("\n===== press Ctrl+C again to force quit =====\n\n")
-	logger.Info().Msgf("successfully stop [%s]!", GetAppName())
+	app.startedStarters = 0
+	errs = append(errs, app.stopComponents(ctx)...)
+	app.state.Store(stateStopped)
+
+	logger.Info().Msgf("successfully stop [%s]!", app.name)
+	return errors.Join(errs...)
+}
+
+// 以下包级函数均作用于默认应用实例，
+// 注册函数出错时不会中断进程，错误会在Run启动应用时返回
+
+// staters shall be "func()", "func() error", "func(context.Context) error" or Lifecycle
+func AddStarters(starters ...interface{}) {
+	appInstance.recordErr(appInstance.AddStarters(starters...))
+}
+
+// sweepers shall be "func()", "func() error", "func(context.Context) error" or Lifecycle
+func AddSweepers(sweepers ...interface{}) {
+	appInstance.recordErr(appInstance.AddSweepers(sweepers...))
+}
+
+func Run() {
+	if err := appInstance.Run(context.Background()); err != nil {
+		logger.Error().Err(err).Msgf("[%s] exited with error", appInstance.name)
+		os.Exit(1)
+	}
+}
+
+func GetAppName() string {
+	return appInstance.Name()
+}
+
+func GetAppVersion() string {
+	return appInstance.Version()
+}
+
+func GetAppDescription() string {
+	return appInstance.Description()
+}
+
+func GetAppAuthor() string {
+	return appInstance.Author()
+}
+
+func GetAppChangeLog() string {
+	return appInstance.ChangeLog()
 }
@@ -0,0 +1,117 @@
+package boot
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// 编译时通过ldflags注入的构建信息，优先于配置文件和go自动记录的vcs信息，例如：
+//
+//	go build -ldflags "-X github.com/why2go/gostarter/boot.buildVersion=v1.2.3 \
+//	  -X github.com/why2go/gostarter/boot.buildRevision=$(git rev-parse HEAD) \
+//	  -X github.com/why2go/gostarter/boot.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	buildVersion  string
+	buildRevision string
+	buildTime     string
+)
+
+// 应用信息，合并了配置文件中的内容和构建信息
+type Info struct {
+	Name        string `json:"name"`
+	Author      string `json:"author"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	ChangeLog   string `json:"changeLog"`
+	Module      string `json:"module,omitempty"`     // 主模块路径
+	Revision    string `json:"revision,omitempty"`   // vcs修订版本
+	Dirty       bool   `json:"dirty"`                // 构建时工作区是否有未提交的修改
+	CommitTime  string `json:"commitTime,omitempty"` // vcs修订版本的提交时间
+	BuildTime   string `json:"buildTime,omitempty"`
+	GoVersion   string `json:"goVersion"`
+}
+
+// 构建信息，进程内只需要读取一次
+type buildMeta struct {
+	version    string
+	module     string
+	revision   string
+	dirty      bool
+	commitTime string
+	buildTime  string
+	goVersion  string
+}
+
+var (
+	buildMetaOnce   sync.Once
+	cachedBuildMeta *buildMeta
+)
+
+func readBuildMeta() *buildMeta {
+	buildMetaOnce.Do(func() {
+		meta := &buildMeta{goVersion: runtime.Version()}
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			meta.module = bi.Main.Path
+			if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+				meta.version = bi.Main.Version
+			}
+			for _, setting := range bi.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					meta.revision = setting.Value
+				case "vcs.time":
+					meta.commitTime = setting.Value
+				case "vcs.modified":
+					meta.dirty = setting.Value == "true"
+				}
+			}
+		}
+		if buildVersion != "" {
+			meta.version = buildVersion
+		}
+		if buildRevision != "" {
+			meta.revision = buildRevision
+		}
+		meta.buildTime = buildTime
+		cachedBuildMeta = meta
+	})
+	return cachedBuildMeta
+}
+
+// 应用信息。版本号的优先级为：WithVersion > ldflags > 配置文件 > 主模块版本
+func (app *App) BuildInfo() *Info {
+	meta := readBuildMeta()
+	return &Info{
+		Name:        app.name,
+		Author:      app.author,
+		Version:     app.version,
+		Description: app.description,
+		ChangeLog:   app.changeLog,
+		Module:      meta.module,
+		Revision:    meta.revision,
+		Dirty:       meta.dirty,
+		CommitTime:  meta.commitTime,
+		BuildTime:   meta.buildTime,
+		GoVersion:   meta.goVersion,
+	}
+}
+
+func (app *App) logBuildInfo() {
+	info := app.BuildInfo()
+	logger.Info().
+		Str("name", info.Name).
+		Str("version", info.Version).
+		Str("module", info.Module).
+		Str("revision", info.Revision).
+		Bool("dirty", info.Dirty).
+		Str("commitTime", info.CommitTime).
+		Str("buildTime", info.BuildTime).
+		Str("goVersion", info.GoVersion).
+		Msg("build info")
+}
+
+// 默认应用实例的应用信息
+func BuildInfo() *Info {
+	return appInstance.BuildInfo()
+}
@@ -0,0 +1,307 @@
+package boot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 具名组件，可以声明对其他组件的依赖，例如 "gin" 依赖 "gorm:primary" 和 "redis:cache"。
+// 启动时，boot根据依赖关系计算拓扑顺序依次执行启动函数，若存在循环依赖则启动失败；
+// 关闭时，按照与启动完全相反的顺序执行清理函数。
+type component struct {
+	name      string
+	dependsOn []string
+	after     []string // 只影响启动顺序，未注册的组件会被忽略
+	starter   *hook
+	sweeper   *hook
+	reporter  HealthReporter // 组件启动之后提供健康检查函数
+	stage     string         // 所属的启动阶段，为空表示单独启动
+	index     int            // 注册顺序，用于保证相同条件下排序结果稳定
+}
+
+type ComponentOption func(*componentOptions)
+
+type componentOptions struct {
+	dependsOn []string
+	after     []string
+	sweeper   interface{}
+	stage     string
+}
+
+// 声明当前组件依赖的其他组件
+func DependsOn(names ...string) ComponentOption {
+	return func(o *componentOptions) {
+		for _, name := range names {
+			o.dependsOn = append(o.dependsOn, strings.TrimSpace(name))
+		}
+	}
+}
+
+// 声明当前组件应该在哪些组件之后启动，与DependsOn不同，未注册的组件会被忽略
+func After(names ...string) ComponentOption {
+	return func(o *componentOptions) {
+		for _, name := range names {
+			o.after = append(o.after, strings.TrimSpace(name))
+		}
+	}
+}
+
+// 设置组件的清理函数，支持的形式同AddSweepers
+func WithSweeper(sweeper interface{}) ComponentOption {
+	return func(o *componentOptions) {
+		o.sweeper = sweeper
+	}
+}
+
+// 将组件放入指定的启动阶段，同一阶段的组件并发启动、并发清理。
+// 阶段之间的先后顺序由其中组件的依赖关系决定，同一阶段内的组件不能相互依赖
+func InStage(stage string) ComponentOption {
+	return func(o *componentOptions) {
+		o.stage = strings.TrimSpace(stage)
+	}
+}
+
+// 注册一个具名组件，支持的形式同AddStarters。
+// 如果starter实现了Lifecycle且没有通过WithSweeper指定清理函数，则使用其Stop方法清理组件
+func (app *App) AddComponent(name string, starter interface{}, opts ...ComponentOption) error {
+	o := &componentOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	c := &component{
+		name:      strings.TrimSpace(name),
+		dependsOn: o.dependsOn,
+		after:     o.after,
+		stage:     o.stage,
+	}
+	if c.name == "" {
+		return errors.New("component name can't be empty")
+	}
+	if _, exists := app.components[c.name]; exists {
+		return fmt.Errorf("duplicate component: %s", c.name)
+	}
+	var err error
+	c.starter, err = newHook(starter, false)
+	if err != nil {
+		return fmt.Errorf("invalid starter of component %s: %w", c.name, err)
+	}
+	c.starter.name = c.name
+	sweeper := o.sweeper
+	if lc, ok := starter.(Lifecycle); ok && sweeper == nil {
+		sweeper = lc
+	}
+	if sweeper != nil {
+		c.sweeper, err = newHook(sweeper, true)
+		if err != nil {
+			return fmt.Errorf("invalid sweeper of component %s: %w", c.name, err)
+		}
+		c.sweeper.name = c.name
+	}
+	if reporter, ok := starter.(HealthReporter); ok {
+		c.reporter = reporter
+	}
+	c.index = len(app.components)
+	app.components[c.name] = c
+	return nil
+}
+
+// 由各个starter实现的组件，通过Use注册后启动和清理自动配对
+type Component interface {
+	Lifecycle
+	Name() string
+}
+
+// 组件可以实现此接口，声明应该在哪些组件之后启动，效果同After
+type OrderedComponent interface {
+	After() []string
+}
+
+// 组件可以实现此接口提供健康检查函数，key为检查项的名字。
+// 组件启动成功之后注册到使用它的应用实例，组件清理时移除
+type HealthReporter interface {
+	HealthCheckers() map[string]HealthChecker
+}
+
+// 注册一个组件，组件的启动和清理自动配对，opts可以追加依赖、启动阶段等设置
+func (app *App) Use(c Component, opts ...ComponentOption) error {
+	if oc, ok := c.(OrderedComponent); ok {
+		opts = append([]ComponentOption{After(oc.After()...)}, opts...)
+	}
+	return app.AddComponent(c.Name(), c, opts...)
+}
+
+// 向默认应用实例注册一个组件，例如：boot.Use(ginstarter.Component())
+func Use(c Component, opts ...ComponentOption) {
+	appInstance.recordErr(appInstance.Use(c, opts...))
+}
+
+// 向默认应用实例注册一个具名组件
+func AddComponent(name string, starter interface{}, opts ...ComponentOption) {
+	appInstance.recordErr(appInstance.AddComponent(name, starter, opts...))
+}
+
+// 按照依赖关系对组件进行拓扑排序，被依赖的组件排在前面
+func sortComponents(components map[string]*component) ([]*component, error) {
+	ordered := make([]*component, 0, len(components))
+	for _, c := range components {
+		ordered = append(ordered, c)
+	}
+	// 按注册顺序遍历，保证排序结果确定
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].index < ordered[j].index })
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[string]int, len(components))
+	sorted := make([]*component, 0, len(components))
+	var path []string
+
+	var visit func(c *component) error
+	visit = func(c *component) error {
+		switch states[c.name] {
+		case visited:
+			return nil
+		case visiting:
+			// 截取环路部分，便于定位
+			for i := range path {
+				if path[i] == c.name {
+					return fmt.Errorf("dependency cycle detected: %s", strings.Join(append(path[i:], c.name), " -> "))
+				}
+			}
+		}
+		states[c.name] = visiting
+		path = append(path, c.name)
+		for _, dep := range c.dependsOn {
+			d, ok := components[dep]
+			if !ok {
+				return fmt.Errorf("component %s depends on unknown component %s", c.name, dep)
+			}
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		for _, dep := range c.after {
+			if d, ok := components[dep]; ok {
+				if err := visit(d); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		states[c.name] = visited
+		sorted = append(sorted, c)
+		return nil
+	}
+
+	for _, c := range ordered {
+		if err := visit(c); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// 一组同时启动的组件，未指定阶段的组件单独构成一个阶段
+type stage struct {
+	name       string
+	components []*component
+}
+
+func (s *stage) String() string {
+	if s.name == "" && len(s.components) == 1 {
+		return s.components[0].name
+	}
+	return s.name
+}
+
+// 并发地对阶段内的每个组件执行fn，返回执行成功的组件以及汇总后的错误
+func (s *stage) forEach(fn func(c *component) error) ([]*component, error) {
+	errs := make([]error, len(s.components))
+	var wg sync.WaitGroup
+	for i, c := range s.components {
+		wg.Add(1)
+		go func(i int, c *component) {
+			defer wg.Done()
+			errs[i] = fn(c)
+		}(i, c)
+	}
+	wg.Wait()
+	var succeeded []*component
+	for i, c := range s.components {
+		if errs[i] == nil {
+			succeeded = append(succeeded, c)
+		}
+	}
+	return succeeded, errors.Join(errs...)
+}
+
+// 将组件划分为阶段，并按照阶段之间的依赖关系排序
+func planStages(components map[string]*component) ([]*stage, error) {
+	sorted, err := sortComponents(components)
+	if err != nil {
+		return nil, err
+	}
+	stageOf := make(map[string]*stage, len(sorted)) // 组件名 -> 阶段
+	var stages []*stage
+	named := make(map[string]*stage)
+	for _, c := range sorted {
+		s, ok := named[c.stage]
+		if !ok || c.stage == "" {
+			s = &stage{name: c.stage}
+			stages = append(stages, s)
+			if c.stage != "" {
+				named[c.stage] = s
+			}
+		}
+		s.components = append(s.components, c)
+		stageOf[c.name] = s
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[*stage]int, len(stages))
+	planned := make([]*stage, 0, len(stages))
+
+	var visit func(s *stage) error
+	visit = func(s *stage) error {
+		switch states[s] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected between stages, involving stage %s", s)
+		}
+		states[s] = visiting
+		for _, c := range s.components {
+			for _, dep := range append(append([]string{}, c.dependsOn...), c.after...) {
+				ds, ok := stageOf[dep]
+				if !ok {
+					continue
+				}
+				if ds == s {
+					return fmt.Errorf("component %s and its dependency %s are in the same stage %s", c.name, dep, s)
+				}
+				if err := visit(ds); err != nil {
+					return err
+				}
+			}
+		}
+		states[s] = visited
+		planned = append(planned, s)
+		return nil
+	}
+
+	for _, s := range stages {
+		if err := visit(s); err != nil {
+			return nil, err
+		}
+	}
+	return planned, nil
+}
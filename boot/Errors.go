@@ -0,0 +1,29 @@
+package boot
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// 注册的启动/清理函数形式不受支持
+type InvalidStarterError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidStarterError) Error() string {
+	return fmt.Sprintf("unsupported type: %s", e.Type)
+}
+
+// 连接外部服务失败，Target为出错的数据源或客户端名称，例如"gorm:default"
+type ConnectionError struct {
+	Target string
+	Err    error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("connect to %s failed: %v", e.Target, e.Err)
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
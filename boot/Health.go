@@ -0,0 +1,180 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 健康检查函数，返回nil表示健康，由各个starter注册，例如检查数据库连接是否可用
+type HealthChecker func(ctx context.Context) error
+
+type HealthStatus string
+
+const (
+	StatusUp   HealthStatus = "UP"
+	StatusDown HealthStatus = "DOWN"
+)
+
+type CheckResult struct {
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// 健康检查的汇总结果
+type HealthReport struct {
+	Status HealthStatus            `json:"status"`
+	State  string                  `json:"state"` // 应用所处的生命周期阶段
+	Checks map[string]*CheckResult `json:"checks,omitempty"`
+}
+
+// 应用所处的生命周期阶段
+const (
+	stateNew int32 = iota
+	stateStarting
+	stateRunning
+	stateStopping
+	stateStopped
+)
+
+var stateNames = map[int32]string{
+	stateNew:      "new",
+	stateStarting: "starting",
+	stateRunning:  "running",
+	stateStopping: "stopping",
+	stateStopped:  "stopped",
+}
+
+type healthRegistry struct {
+	mu         sync.RWMutex
+	checkers   map[string]HealthChecker
+	components map[string]map[string]HealthChecker // 已启动组件提供的健康检查函数，key为组件名
+}
+
+func (r *healthRegistry) setComponent(name string, checkers map[string]HealthChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.components == nil {
+		r.components = make(map[string]map[string]HealthChecker)
+	}
+	r.components[name] = checkers
+}
+
+func (r *healthRegistry) removeComponent(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.components, name)
+}
+
+// 注册一个具名的健康检查函数，名字重复时返回错误
+func (app *App) AddHealthChecker(name string, checker HealthChecker) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("health checker name can't be empty")
+	}
+	if checker == nil {
+		return fmt.Errorf("health checker %s is nil", name)
+	}
+	app.health.mu.Lock()
+	defer app.health.mu.Unlock()
+	if _, exists := app.health.checkers[name]; exists {
+		return fmt.Errorf("duplicate health checker: %s", name)
+	}
+	app.health.checkers[name] = checker
+	return nil
+}
+
+// 存活状态，只反映应用的生命周期阶段，不执行健康检查函数，应用清理完毕后为DOWN
+func (app *App) Liveness() *HealthReport {
+	state := app.state.Load()
+	report := &HealthReport{Status: StatusUp, State: stateNames[state]}
+	if state == stateStopped {
+		report.Status = StatusDown
+	}
+	return report
+}
+
+// 就绪状态，应用启动完成且所有健康检查都通过时为UP。
+// 应用开始清理后立即变为DOWN，以便负载均衡在清理函数执行之前摘除流量
+func (app *App) Readiness(ctx context.Context) *HealthReport {
+	report := app.Health(ctx)
+	if app.state.Load() != stateRunning {
+		report.Status = StatusDown
+	}
+	return report
+}
+
+// 并发执行所有健康检查函数，返回每一项的结果
+func (app *App) Health(ctx context.Context) *HealthReport {
+	app.health.mu.RLock()
+	all := make(map[string]HealthChecker, len(app.health.checkers))
+	for _, checkers := range app.health.components {
+		for name, checker := range checkers {
+			all[name] = checker
+		}
+	}
+	// 名字相同时以AddHealthChecker注册的为准
+	for name, checker := range app.health.checkers {
+		all[name] = checker
+	}
+	app.health.mu.RUnlock()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checkers := make([]HealthChecker, len(names))
+	for i, name := range names {
+		checkers[i] = all[name]
+	}
+
+	results := make([]*CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i := range checkers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = &CheckResult{Status: StatusUp}
+			if err := checkers[i](ctx); err != nil {
+				results[i] = &CheckResult{Status: StatusDown, Error: err.Error()}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	report := &HealthReport{
+		Status: StatusUp,
+		State:  stateNames[app.state.Load()],
+		Checks: make(map[string]*CheckResult, len(names)),
+	}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// 向默认应用实例注册一个具名的健康检查函数
+func AddHealthChecker(name string, checker HealthChecker) {
+	appInstance.recordErr(appInstance.AddHealthChecker(name, checker))
+}
+
+// 默认应用实例的存活状态
+func Liveness() *HealthReport {
+	return appInstance.Liveness()
+}
+
+// 默认应用实例的就绪状态
+func Readiness(ctx context.Context) *HealthReport {
+	return appInstance.Readiness(ctx)
+}
+
+// 默认应用实例的健康检查结果
+func Health(ctx context.Context) *HealthReport {
+	return appInstance.Health(ctx)
+}
@@ -0,0 +1,82 @@
+package boot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// 带有上下文的生命周期接口，可以和"func()"、"func() error"形式的启动/清理函数混合使用。
+// Start(ctx)中的ctx在启动阶段结束后即被取消，不要用它来控制长期运行的任务。
+type Lifecycle interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// 统一后的启动或清理函数
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// 将支持的函数形式转换为hook，stop为true时取Lifecycle的Stop方法，否则取Start方法。
+// 支持的形式有："func()", "func() error", "func(context.Context) error", Lifecycle
+func newHook(f interface{}, stop bool) (*hook, error) {
+	h := &hook{}
+	switch v := f.(type) {
+	case func():
+		h.name = funcName(v)
+		h.fn = func(context.Context) error { v(); return nil }
+	case func() error:
+		h.name = funcName(v)
+		h.fn = func(context.Context) error { return v() }
+	case func(context.Context) error:
+		h.name = funcName(v)
+		h.fn = v
+	case Lifecycle:
+		h.name = fmt.Sprintf("%T", v)
+		if stop {
+			h.fn = v.Stop
+		} else {
+			h.fn = v.Start
+		}
+	default:
+		return nil, &InvalidStarterError{Type: reflect.TypeOf(f)}
+	}
+	return h, nil
+}
+
+func funcName(f interface{}) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return reflect.TypeOf(f).String()
+}
+
+// 执行hook，timeout大于0时限定其执行时长。
+// 超时后不再等待hook返回，记录超时的hook并返回错误。
+func runHook(ctx context.Context, h *hook, timeout time.Duration) error {
+	hookCtx := ctx
+	if timeout > 0 {
+		var cf context.CancelFunc
+		hookCtx, cf = context.WithTimeout(ctx, timeout)
+		defer cf()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(hookCtx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-hookCtx.Done():
+		if ctx.Err() != nil {
+			logger.Error().Str("hook", h.name).Msg("hook exceeded the global deadline")
+		} else {
+			logger.Error().Str("hook", h.name).Msgf("hook exceeded its budget of %s", timeout)
+		}
+		return fmt.Errorf("hook %s: %w", h.name, hookCtx.Err())
+	}
+}
@@ -0,0 +1,130 @@
+package boot
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/why2go/gostarter/config"
+)
+
+// 连接外部服务时的重试策略，各个starter在数据源的retry配置项中使用，例如：
+//
+//	gorm:
+//	  db0:
+//	    dsn: ...
+//	    retry:
+//	      maxAttempts: 10      # 最大尝试次数，默认3
+//	      initialInterval: 1s  # 首次重试前的等待时间，默认500ms
+//	      maxInterval: 15s     # 等待时间的上限，默认10s
+//	      multiplier: 2        # 每次重试后等待时间的增长倍数，默认2
+//	      jitter: 0.2          # 等待时间的随机浮动比例，取值[0, 1]，默认0.2
+//	      deadline: 1m         # 所有尝试的总时限，默认不限制
+//
+// 没有配置retry时只尝试一次
+type RetryPolicy struct {
+	MaxAttempts     int             `yaml:"maxAttempts" json:"maxAttempts" validate:"min=0"`
+	InitialInterval config.Duration `yaml:"initialInterval" json:"initialInterval" validate:"min=0s"`
+	MaxInterval     config.Duration `yaml:"maxInterval" json:"maxInterval" validate:"min=0s"`
+	Multiplier      float64         `yaml:"multiplier" json:"multiplier" validate:"min=0"`
+	Jitter          *float64        `yaml:"jitter" json:"jitter" validate:"min=0,max=1"`
+	Deadline        config.Duration `yaml:"deadline" json:"deadline" validate:"min=0s"`
+}
+
+var (
+	defaultRetryAttempts        = 3
+	defaultRetryInitialInterval = 500 * time.Millisecond
+	defaultRetryMaxInterval     = 10 * time.Second
+	defaultRetryMultiplier      = 2.0
+	defaultRetryJitter          = 0.2
+)
+
+// 解析后的重试策略
+type backoff struct {
+	maxAttempts int
+	interval    time.Duration
+	maxInterval time.Duration
+	multiplier  float64
+	jitter      float64
+	deadline    time.Duration
+}
+
+func (p *RetryPolicy) backoff() (*backoff, error) {
+	b := &backoff{
+		maxAttempts: defaultRetryAttempts,
+		interval:    defaultRetryInitialInterval,
+		maxInterval: defaultRetryMaxInterval,
+		multiplier:  defaultRetryMultiplier,
+		jitter:      defaultRetryJitter,
+	}
+	if p.MaxAttempts > 0 {
+		b.maxAttempts = p.MaxAttempts
+	}
+	if p.InitialInterval > 0 {
+		b.interval = p.InitialInterval.Std()
+	}
+	if p.MaxInterval > 0 {
+		b.maxInterval = p.MaxInterval.Std()
+	}
+	if p.Deadline > 0 {
+		b.deadline = p.Deadline.Std()
+	}
+	if p.Multiplier >= 1 {
+		b.multiplier = p.Multiplier
+	}
+	if p.Jitter != nil {
+		if *p.Jitter < 0 || *p.Jitter > 1 {
+			return nil, fmt.Errorf("invalid retry jitter: %v", *p.Jitter)
+		}
+		b.jitter = *p.Jitter
+	}
+	return b, nil
+}
+
+// 第attempt次失败之后的等待时间
+func (b *backoff) wait(attempt int) time.Duration {
+	d := float64(b.interval)
+	for i := 1; i < attempt && d < float64(b.maxInterval); i++ {
+		d *= b.multiplier
+	}
+	if d > float64(b.maxInterval) {
+		d = float64(b.maxInterval)
+	}
+	if b.jitter > 0 {
+		d += d * b.jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// 按照重试策略执行fn，直到成功、达到最大尝试次数、超出总时限或者ctx结束，返回最后一次的错误。
+// p为nil时只执行一次，name用于日志，一般为数据源名称，例如"gorm:db0"
+func (p *RetryPolicy) Do(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	if p == nil {
+		return fn(ctx)
+	}
+	b, err := p.backoff()
+	if err != nil {
+		return err
+	}
+	ctx, cf := withOptionalTimeout(ctx, b.deadline)
+	defer cf()
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= b.maxAttempts {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+		wait := b.wait(attempt)
+		logger.Warn().Err(err).Str("target", name).Int("attempt", attempt).Msgf("retry in %s", wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+	}
+}
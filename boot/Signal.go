@@ -0,0 +1,152 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// 信号处理：
+//   - 收到signals中的信号时开始清理应用，清理过程中再次收到信号则立即强制退出
+//   - 收到reloadSignals中的信号时，按注册顺序执行通过AddReloadHooks注册的函数，例如重新读取配置、重新打开日志文件
+//
+// 配置如下：
+//
+//	app:
+//	  signals: [SIGINT, SIGTERM] # 默认值
+//	  reloadSignals: [SIGHUP]    # 默认值
+//
+// 支持SIGHUP、SIGINT、SIGQUIT、SIGTERM，以及unix上的SIGUSR1、SIGUSR2
+
+var (
+	knownSignals = mergeSignals(map[string]os.Signal{
+		"SIGHUP":  syscall.SIGHUP,
+		"SIGINT":  syscall.SIGINT,
+		"SIGQUIT": syscall.SIGQUIT,
+		"SIGTERM": syscall.SIGTERM,
+	}, platformSignals())
+
+	defaultSignals       = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	defaultReloadSignals = []os.Signal{syscall.SIGHUP}
+
+	// 强制退出进程，测试时替换
+	osExit = os.Exit
+)
+
+func mergeSignals(dst, src map[string]os.Signal) map[string]os.Signal {
+	for name, sig := range src {
+		dst[name] = sig
+	}
+	return dst
+}
+
+func parseSignals(names []string) ([]os.Signal, error) {
+	sigs := make([]os.Signal, 0, len(names))
+	for _, name := range names {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !strings.HasPrefix(name, "SIG") {
+			name = "SIG" + name
+		}
+		sig, ok := knownSignals[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported signal: %s", name)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+func containsSignal(sigs []os.Signal, sig os.Signal) bool {
+	for _, s := range sigs {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// 注册重新加载时执行的函数，支持"func()"、"func() error"和"func(context.Context) error"
+func (app *App) AddReloadHooks(hooks ...interface{}) error {
+	for _, f := range hooks {
+		if _, ok := f.(Lifecycle); ok {
+			return errors.New("invalid reload hook: Lifecycle is not supported")
+		}
+		h, err := newHook(f, false)
+		if err != nil {
+			return fmt.Errorf("invalid reload hook: %w", err)
+		}
+		app.reloadHooks = append(app.reloadHooks, h)
+	}
+	return nil
+}
+
+// 按注册顺序执行重新加载函数，某个函数失败不会中断后续函数的执行，返回汇总后的错误
+func (app *App) Reload(ctx context.Context) error {
+	logger.Info().Msgf("[%s] is reloading", app.name)
+	var errs []error
+	for _, h := range app.reloadHooks {
+		if err := runHook(ctx, h, app.timeoutOf(h)); err != nil {
+			logger.Error().Err(err).Msgf("reload hook %s failed", h.name)
+			errs = append(errs, fmt.Errorf("reload hook %s failed: %w", h.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 阻塞直到ctx结束或者收到退出信号，期间收到重新加载信号时执行重新加载函数。
+// 返回的stop用于在清理完成后停止监听信号，在此之前再次收到退出信号会强制退出进程
+func (app *App) waitForSignal(ctx context.Context) (stop func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, append(append([]os.Signal{}, app.signals...), app.reloadSignals...)...)
+
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			break wait
+		case sig := <-sigCh:
+			if containsSignal(app.reloadSignals, sig) {
+				logger.Info().Msgf("received signal %s", sig)
+				_ = app.Reload(ctx)
+				continue
+			}
+			logger.Info().Msgf("received signal %s", sig)
+			break wait
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-sigCh:
+				if containsSignal(app.signals, sig) {
+					logger.Warn().Msgf("received signal %s again, force quit", sig)
+					osExit(1)
+					return
+				}
+			}
+		}
+	}()
+	fmt.Printf("\n===== press Ctrl+C again to force quit =====\n\n")
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+// 向默认应用实例注册重新加载时执行的函数
+func AddReloadHooks(hooks ...interface{}) {
+	appInstance.recordErr(appInstance.AddReloadHooks(hooks...))
+}
+
+// 执行默认应用实例的重新加载函数
+func Reload(ctx context.Context) error {
+	return appInstance.Reload(ctx)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package boot
+
+import "os"
+
+func platformSignals() map[string]os.Signal {
+	return nil
+}
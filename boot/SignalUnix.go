@@ -0,0 +1,17 @@
+//go:build unix
+
+package boot
+
+import (
+	"os"
+	"syscall"
+)
+
+// windows上没有SIGUSR1和SIGUSR2。
+// 在变量初始化时合并到knownSignals中，早于init中创建默认应用实例
+func platformSignals() map[string]os.Signal {
+	return map[string]os.Signal{
+		"SIGUSR1": syscall.SIGUSR1,
+		"SIGUSR2": syscall.SIGUSR2,
+	}
+}
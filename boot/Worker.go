@@ -0,0 +1,156 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// 后台任务，例如定时任务、消息队列的消费者。boot在后台执行Run并按照重启策略监管它，
+// 应用清理时取消Run的ctx并等待其返回。例如：
+//
+//	boot.AddWorker(boot.Worker{
+//		Name:    "order-consumer",
+//		Run:     consumeOrders,
+//		Restart: boot.RestartOnFailure,
+//		Backoff: &boot.RetryPolicy{InitialInterval: config.Duration(time.Second), MaxInterval: config.Duration(time.Minute)},
+//	}, boot.After("kafka"))
+//
+// worker作为具名组件注册，可以使用DependsOn、After和InStage控制启动顺序，但不能使用WithSweeper
+type Worker struct {
+	Name    string
+	Run     func(ctx context.Context) error
+	Restart RestartPolicy // 默认RestartNever
+	// 重启前的等待策略，MaxAttempts表示最多重启的次数，为0时不限制，Deadline无效。
+	// 为nil时使用RetryPolicy的默认值且不限制重启次数
+	Backoff *RetryPolicy
+}
+
+// worker的重启策略
+type RestartPolicy string
+
+const (
+	RestartNever     RestartPolicy = "never"      // Run返回后不再重启
+	RestartOnFailure RestartPolicy = "on-failure" // Run返回错误或者panic时重启
+	RestartAlways    RestartPolicy = "always"     // Run返回后总是重启
+)
+
+type worker struct {
+	Worker
+	backoff *backoff
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newWorker(w Worker) (*worker, error) {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Run == nil {
+		return nil, fmt.Errorf("worker %s has no Run function", w.Name)
+	}
+	switch w.Restart {
+	case "":
+		w.Restart = RestartNever
+	case RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		return nil, fmt.Errorf("invalid restart policy of worker %s: %s", w.Name, w.Restart)
+	}
+	policy := w.Backoff
+	if policy == nil {
+		policy = &RetryPolicy{}
+	}
+	b, err := policy.backoff()
+	if err != nil {
+		return nil, fmt.Errorf("invalid backoff of worker %s: %w", w.Name, err)
+	}
+	if policy.MaxAttempts <= 0 {
+		b.maxAttempts = 0
+	}
+	return &worker{Worker: w, backoff: b}, nil
+}
+
+// 启动阶段的ctx在启动结束后就会被取消，worker使用独立的ctx，在清理时取消
+func (w *worker) Start(context.Context) error {
+	ctx, cf := context.WithCancel(context.Background())
+	w.cancel = cf
+	w.done = make(chan struct{})
+	go w.supervise(ctx)
+	return nil
+}
+
+func (w *worker) Stop(ctx context.Context) error {
+	w.cancel()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		logger.Warn().Str("worker", w.Name).Msg("worker did not exit in time")
+		return ctx.Err()
+	}
+}
+
+func (w *worker) supervise(ctx context.Context) {
+	defer close(w.done)
+	for restarts := 0; ; restarts++ {
+		err := w.runOnce(ctx)
+		if ctx.Err() != nil {
+			logger.Info().Str("worker", w.Name).Msg("worker exited")
+			return
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("worker", w.Name).Msg("worker crashed")
+		} else {
+			logger.Info().Str("worker", w.Name).Msg("worker returned")
+		}
+		if w.Restart == RestartNever || (w.Restart == RestartOnFailure && err == nil) {
+			return
+		}
+		if w.backoff.maxAttempts > 0 && restarts >= w.backoff.maxAttempts {
+			logger.Error().Str("worker", w.Name).Msgf("worker gave up after %d restarts", restarts)
+			return
+		}
+		wait := w.backoff.wait(restarts + 1)
+		logger.Info().Str("worker", w.Name).Msgf("restart worker in %s", wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// 执行一次Run，panic会被转换为错误
+func (w *worker) runOnce(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().Str("worker", w.Name).Str("stack", string(debug.Stack())).Msgf("worker panic: %v", r)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return w.Run(ctx)
+}
+
+// 注册一个worker，应用启动时开始执行，清理时取消
+func (app *App) AddWorker(w Worker, opts ...ComponentOption) error {
+	o := &componentOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.sweeper != nil {
+		return errors.New("worker can't have a sweeper")
+	}
+	wk, err := newWorker(w)
+	if err != nil {
+		return err
+	}
+	return app.AddComponent(wk.Name, wk, opts...)
+}
+
+// 向默认应用实例注册一个worker
+func AddWorker(w Worker, opts ...ComponentOption) {
+	appInstance.recordErr(appInstance.AddWorker(w, opts...))
+}
@@ -0,0 +1,56 @@
+package boot
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+// 替换构建信息，模拟主模块版本和ldflags注入的版本
+func fakeBuildMeta(t *testing.T, moduleVersion, ldflagsVersion string) {
+	readBuildMeta()
+	oldMeta, oldVersion := cachedBuildMeta, buildVersion
+	t.Cleanup(func() {
+		cachedBuildMeta, buildVersion = oldMeta, oldVersion
+	})
+	meta := *oldMeta
+	meta.version = moduleVersion
+	if ldflagsVersion != "" {
+		meta.version = ldflagsVersion
+	}
+	cachedBuildMeta, buildVersion = &meta, ldflagsVersion
+}
+
+func newVersionedApp(t *testing.T, configVersion string, opts ...Option) *App {
+	source := func(c config.Configurable) error {
+		if c.ConfigName() == "app" {
+			return json.Unmarshal([]byte(`{"name": "version test", "version": "`+configVersion+`"}`), c)
+		}
+		return config.ErrNoConfigItemFound
+	}
+	app, err := New(append([]Option{WithConfigSource(source)}, opts...)...)
+	assert.Nil(t, err)
+	return app
+}
+
+func TestBuildInfo(t *testing.T) {
+	info := newVersionedApp(t, "v0.1.0").BuildInfo()
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, "version test", info.Name)
+
+	t.Run("ModuleVersion", func(t *testing.T) {
+		fakeBuildMeta(t, "v1.0.0", "")
+		assert.Equal(t, "v1.0.0", newVersionedApp(t, "").Version())
+		assert.Equal(t, "v0.1.0", newVersionedApp(t, "v0.1.0").Version())
+	})
+
+	t.Run("Ldflags", func(t *testing.T) {
+		fakeBuildMeta(t, "v1.0.0", "v2.0.0")
+		assert.Equal(t, "v2.0.0", newVersionedApp(t, "v0.1.0").Version())
+		assert.Equal(t, "v3.0.0", newVersionedApp(t, "v0.1.0", WithVersion("v3.0.0")).Version())
+		assert.Equal(t, "v2.0.0", newVersionedApp(t, "").BuildInfo().Version)
+	})
+}
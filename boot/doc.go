@@ -6,4 +6,74 @@
 //	  version: v0.0.1
 //	  description: echo server
 //	  changeLog: ""
+//	  startupTimeout: 1m    # 启动阶段的整体时限，默认不限制
+//	  shutdownTimeout: 30s  # 清理阶段的整体时限，默认5m
+//	  hookTimeout: 10s      # 单个启动/清理函数的默认时限，默认不限制
+//	  hookTimeouts:         # 为指定的组件或函数单独设置时限
+//	    gin: 20s
+//	  drainDelay: 5s        # 就绪状态变为DOWN之后，等待多久再执行清理函数，默认不等待
+//	  signals: [SIGINT, SIGTERM] # 触发清理的信号，清理过程中再次收到会强制退出
+//	  reloadSignals: [SIGHUP]    # 触发AddReloadHooks注册的函数
+//
+// 可以注册具名组件并声明依赖关系，boot会按照拓扑顺序启动组件，按相反顺序清理组件：
+//
+//	boot.AddComponent("gorm:primary", startGorm, boot.WithSweeper(stopGorm))
+//	boot.AddComponent("redis:cache", startRedis)
+//	boot.AddComponent("gin", ginstarter.StartHttpServer,
+//	  boot.WithSweeper(ginstarter.StopHttpServer),
+//	  boot.DependsOn("gorm:primary", "redis:cache"))
+//
+// 各个starter都提供了Component()，通过Use注册后启动和清理自动配对，服务类组件会在数据源之后启动：
+//
+//	boot.Use(gormstarter.Component())
+//	boot.Use(goredisstarter.Component())
+//	boot.Use(ginstarter.Component())
+//
+// 互不依赖的组件可以放入同一个启动阶段，同一阶段的组件并发启动、并发清理，
+// 阶段内的错误汇总后才会进入回滚。阶段之间的顺序同样由依赖关系决定：
+//
+//	boot.AddComponent("gorm:primary", startPrimary, boot.InStage("db"))
+//	boot.AddComponent("gorm:secondary", startSecondary, boot.InStage("db"))
+//	boot.AddComponent("gin", ginstarter.StartHttpServer, boot.DependsOn("gorm:primary", "gorm:secondary"))
+//
+// 启动/清理函数可以是"func()"、"func() error"、"func(context.Context) error"或者实现了Lifecycle的对象，
+// 超出时限的函数会被记录在日志中。
+//
+// 通过AddStarters添加的启动函数在所有具名组件启动之后按注册顺序执行，
+// 通过AddSweepers添加的清理函数在具名组件清理之前按注册顺序执行。
+// 两者按注册顺序一一对应，某个启动函数失败时，逆序执行之前已成功的启动函数对应的清理函数。
+//
+// 定时任务、消息队列消费者等后台任务可以通过AddWorker注册，boot按照重启策略（never/on-failure/always）
+// 监管其运行，记录崩溃日志，并在应用清理时取消其ctx。
+//
+// BuildInfo返回合并了配置和构建信息（vcs修订版本、构建时间、go版本等）的应用信息，启动时会打印一次，
+// 可以在编译时通过ldflags注入版本号、修订版本和构建时间，详见BuildInfo.go。
+//
+// 各个starter通过AddHealthChecker注册具名的健康检查函数，Liveness、Readiness和Health汇总应用的健康状态。
+// 通过Use注册的组件如果实现了HealthReporter，其健康检查函数在组件启动之后注册到该应用实例，清理时移除。
+// 开始清理时就绪状态立即变为DOWN，以便负载均衡在清理函数执行之前摘除流量。
+//
+// 配置了admin项并启用时，boot会在独立的端口上提供/healthz、/readyz、/info和/config接口：
+//
+//	admin:
+//	  enabled: true
+//	  port: 8090
+//	  redactKeys: [phone, idcard] # 除了password、secret、token、key等常见名称之外，还需要隐藏的配置项
+//
+// /config中名字看起来像密钥的配置项都会被隐藏，url中的密码也会被隐藏。
+//
+// 包级函数作用于默认应用实例，也可以通过New创建独立的应用实例，便于在测试中启停：
+//
+//	app, err := boot.New(boot.WithName("echo"), boot.WithVersion("v0.0.2"))
+//	app.AddComponent("gin", ginstarter.StartHttpServer, boot.WithSweeper(ginstarter.StopHttpServer))
+//	err = app.Start(ctx)
+//	// ...
+//	err = app.Stop(ctx)
+//
+// boot和各个starter都不会主动退出进程，错误逐层返回，由应用决定退出、重试还是降级：
+//   - *config.ConfigError：配置文件或配置项有误
+//   - *ConnectionError：starter连接外部服务失败
+//   - *InvalidStarterError：注册了不支持的启动/清理函数
+//
+// 通过包级函数注册时出现的错误会暂存在默认应用实例中，由Start/Run返回。
 package boot
@@ -0,0 +1,78 @@
+//go:build unix
+
+package boot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForSignal(t *testing.T) {
+	// 保证测试进程不会因为信号的默认行为退出
+	guard := make(chan os.Signal, 16)
+	signal.Notify(guard, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(guard)
+
+	app, err := newApp(&appConf{Signals: []string{"usr1"}, ReloadSignals: []string{"SIGUSR2"}})
+	assert.Nil(t, err)
+	var reloads atomic.Int32
+	assert.Nil(t, app.AddReloadHooks(func() { reloads.Add(1) }))
+
+	exitCodes := make(chan int, 1)
+	osExit = func(code int) { exitCodes <- code }
+	defer func() { osExit = os.Exit }()
+
+	stopped := make(chan func(), 1)
+	go func() {
+		stopped <- app.waitForSignal(context.Background())
+	}()
+
+	// 开始监听之前发出的信号会丢失，持续发送直到重新加载函数被执行
+	assert.Eventually(t, func() bool {
+		_ = syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+		return reloads.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	var stop func()
+	select {
+	case stop = <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop signal not handled")
+	}
+	defer stop()
+
+	// 清理过程中再次收到退出信号时强制退出
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	select {
+	case code := <-exitCodes:
+		assert.Equal(t, 1, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("force quit not triggered")
+	}
+}
+
+// 默认应用实例在init中创建，需要在子进程中使用临时的配置目录
+func TestDefaultAppSignals(t *testing.T) {
+	if os.Getenv("BOOT_SIGNALS_CHILD") != "" {
+		assert.Empty(t, appInstance.initErrs)
+		assert.Equal(t, []os.Signal{syscall.SIGUSR1}, appInstance.signals)
+		assert.Equal(t, []os.Signal{syscall.SIGUSR2}, appInstance.reloadSignals)
+		return
+	}
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("app:\n  signals: [SIGUSR1]\n  reloadSignals: [SIGUSR2]\n"), 0644))
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDefaultAppSignals$")
+	cmd.Env = append(os.Environ(), "BOOT_SIGNALS_CHILD=1", "CONFIG_DIR="+dir)
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
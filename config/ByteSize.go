@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 配置文件中的字节数，可以写作数字或者带单位的字符串，单位不区分大小写，例如：
+//
+//	4096、"512B"、"64KiB"、"1.5MiB"、"2GB"
+//
+// KiB、MiB、GiB、TiB以及简写K、M、G、T按1024进位，KB、MB、GB、TB按1000进位
+type ByteSize uint64
+
+const (
+	KiB ByteSize = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+	TiB
+)
+
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   float64(KiB),
+	"kib": float64(KiB),
+	"kb":  1e3,
+	"m":   float64(MiB),
+	"mib": float64(MiB),
+	"mb":  1e6,
+	"g":   float64(GiB),
+	"gib": float64(GiB),
+	"gb":  1e9,
+	"t":   float64(TiB),
+	"tib": float64(TiB),
+	"tb":  1e12,
+}
+
+func (s ByteSize) Int() int {
+	if uint64(s) > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(s)
+}
+
+func (s ByteSize) String() string {
+	for _, unit := range []struct {
+		size ByteSize
+		name string
+	}{{TiB, "TiB"}, {GiB, "GiB"}, {MiB, "MiB"}, {KiB, "KiB"}} {
+		if s >= unit.size && s%unit.size == 0 {
+			return fmt.Sprintf("%d%s", s/unit.size, unit.name)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(s))
+}
+
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	idx := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if idx < 0 {
+		idx = len(s)
+	}
+	num, unit := s[:idx], strings.ToLower(strings.TrimSpace(s[idx:]))
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in byte size %q", s)
+	}
+	size := n * multiplier
+	if size > math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q overflows", s)
+	}
+	return ByteSize(size), nil
+}
+
+func (s ByteSize) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+func (s *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("invalid byte size at line %d", value.Line)
+	}
+	parsed, err := ParseByteSize(value.Value)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+func (s ByteSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *ByteSize) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		var n json.Number
+		if numErr := json.Unmarshal(b, &n); numErr != nil {
+			return err
+		}
+		str = n.String()
+	}
+	parsed, err := ParseByteSize(str)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
@@ -1,11 +1,11 @@
 package config
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 )
@@ -18,27 +18,85 @@ type Configurable interface {
 	ConfigName() string
 }
 
-// 获取一项配置内容，c必须是一个指针
+// 获取一项配置内容，c必须是一个指针。解析之前会应用默认值，详见Default.go；
+// 解析之后会按照validate tag和Validator接口校验配置项，详见Validate.go。
+// 配置项不存在时返回ErrNoConfigItemFound，此时c中的零值字段被设置为默认值；其他错误均为*ConfigError，此时c不会被修改
 func GetConfig(c Configurable) error {
-	return defaultHelper.getConfig(c)
+	helper, err := currentHelper()
+	if err != nil {
+		return &ConfigError{Name: c.ConfigName(), Err: err}
+	}
+	return helper.getConfig(c)
+}
+
+// 返回配置文件中的全部配置项，返回值是一份拷贝，修改它不会影响已加载的配置
+func AllSettings() (map[string]interface{}, error) {
+	helper, err := currentHelper()
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+	return helper.allSettings()
+}
+
+var (
+	helperMu         sync.RWMutex // 重新加载配置时替换defaultHelper
+	defaultHelper    *configHelper
+	defaultHelperErr error // 加载配置文件时出现的错误，由GetConfig返回
+)
+
+func currentHelper() (*configHelper, error) {
+	helperMu.RLock()
+	defer helperMu.RUnlock()
+	return defaultHelper, defaultHelperErr
 }
 
-var defaultHelper *configHelper
+func swapHelper(ch *configHelper) {
+	helperMu.Lock()
+	defer helperMu.Unlock()
+	defaultHelper, defaultHelperErr = ch, nil
+}
 
 func init() {
 	ch, err := newConfigHelper()
 	if err != nil {
-		log.Fatal().Err(err).Msg("load config file failed")
+		log.Error().Err(err).Msg("load config file failed")
+		defaultHelperErr = err
 		return
 	}
 	defaultHelper = ch
 }
 
+// 注册了新的文件格式之后重新加载配置文件
+func reloadDefaultHelper() {
+	ch, err := newConfigHelper()
+	old, _ := currentHelper()
+	if err != nil {
+		// 新格式没有对应的配置文件时，保留已加载的配置
+		if old != nil {
+			log.Warn().Err(err).Msg("reload config file failed, keep the loaded config")
+			return
+		}
+		helperMu.Lock()
+		defaultHelperErr = err
+		helperMu.Unlock()
+		return
+	}
+	if old != nil && old.parsedCount() != 0 {
+		log.Warn().Msg("config file reloaded after some configs were read, they may be stale")
+	}
+	swapHelper(ch)
+}
+
 type configHelper struct {
 	configNameRegexp   *regexp.Regexp         // configName应该满足的命名规则
-	rawConfigData      []byte                 // 加载的配置文件内容
-	rawConfigEntries   map[string]interface{} // 通过configName来列出各个配置内容条目
-	fileFormat         *fileFormat            // 配置文件格式
+	layers             []*configLayer         // 按优先级从低到高排列的各层配置文件
+	fileEntries        map[string]interface{} // 合并各层配置文件之后，通过configName来列出各个配置内容条目
+	envOverrides       []*envOverride         // 覆盖配置项的环境变量，在解析配置项时按类型应用
+	rawConfigEntries   map[string]interface{} // 应用了环境变量之后的全部配置条目，用于AllSettings
+	fileFormat         *FileFormat            // 解析配置项使用的格式，与优先级最低的配置文件相同
+	watchPaths         []string               // 所有可能的配置文件路径，用于监听变化
+	signature          string                 // 加载时配置文件的状态
+	mu                 sync.Mutex             // 保护cachedParsedConfig
 	cachedParsedConfig map[string]interface{} // 用于快速检索已经被解析的配置项
 }
 
@@ -47,63 +105,146 @@ func newConfigHelper() (*configHelper, error) {
 	if err != nil {
 		return nil, err
 	}
-	rawConfig, fileFormat, err := l.load()
+	// 在读取文件之前记录文件状态，避免遗漏加载过程中发生的修改
+	watchPaths := l.candidatePaths()
+	signature := fileSignature(watchPaths)
+	layers, err := l.load()
 	if err != nil {
 		return nil, err
 	}
-	entries := make(map[string]interface{})
-	err = fileFormat.parser.Unmarshal(rawConfig, &entries)
-	if err != nil {
-		return nil, err
+	fileEntries := make(map[string]interface{})
+	for _, layer := range layers {
+		mergeEntries(fileEntries, layer.entries)
 	}
+	overrides := loadEnvOverrides()
+	entries := make(map[string]interface{})
+	mergeEntries(entries, fileEntries)
+	applyEnvOverrides(entries, overrides)
 	helper := &configHelper{
 		configNameRegexp:   regexp.MustCompile(`^([A-Za-z0-9._~-]+\/)*[A-Za-z0-9._~-]+$`),
-		rawConfigData:      rawConfig,
+		layers:             layers,
+		fileEntries:        fileEntries,
+		envOverrides:       overrides,
 		rawConfigEntries:   entries,
-		fileFormat:         fileFormat,
+		fileFormat:         layers[0].format,
+		watchPaths:         watchPaths,
+		signature:          signature,
 		cachedParsedConfig: make(map[string]interface{}),
 	}
 	return helper, nil
 }
 
-var (
-	ErrMalformedConfigName = errors.New("malformed config name")
-	ErrNoConfigItemFound   = errors.New("no config item found")
-)
-
 // 传入一个指针，修改这个指针的值
 func (helper *configHelper) getConfig(c Configurable) error {
 	configName := strings.TrimSpace(c.ConfigName())
 	if configName == "" || len(configName) > 512 ||
 		!helper.configNameRegexp.MatchString(configName) {
-		return ErrMalformedConfigName
+		return &ConfigError{Name: configName, Err: ErrMalformedConfigName}
 	}
 	rv := reflect.ValueOf(c)
 	if rv.Kind() != reflect.Pointer {
-		return fmt.Errorf("non-pointer param, type: %s", reflect.TypeOf(c).String())
+		return &ConfigError{Name: configName, Err: fmt.Errorf("non-pointer param, type: %s", reflect.TypeOf(c).String())}
 	}
 	ctyp := reflect.TypeOf(c)
+	helper.mu.Lock()
+	defer helper.mu.Unlock()
 	if parsedConf, exists := helper.cachedParsedConfig[configName]; exists {
 		ptyp := reflect.TypeOf(parsedConf)
 		if ctyp != ptyp {
-			return fmt.Errorf(`conflict config name, their type are "%s" and "%s"`, ctyp, ptyp)
+			return &ConfigError{Name: configName, Err: fmt.Errorf(`conflict config name, their type are "%s" and "%s"`, ctyp, ptyp)}
 		} else {
 			rv.Elem().Set(reflect.ValueOf(parsedConf).Elem())
 		}
 	} else {
-		if inf, exists := helper.rawConfigEntries[configName]; exists {
-			parser := helper.fileFormat.parser
-			b, _ := parser.Marshal(inf)
-			v := reflect.New(ctyp.Elem())
-			err := parser.Unmarshal(b, v.Interface())
-			if err != nil {
-				return err
+		inf, err := helper.entryOf(configName, ctyp)
+		if err != nil {
+			return &ConfigError{Name: configName, Err: err}
+		}
+		if inf == nil {
+			// 配置项不存在时，仍然为c中的零值字段设置默认值，以便调用方直接使用
+			if err = SetDefaults(c); err != nil {
+				return &ConfigError{Name: configName, Err: err}
 			}
-			helper.cachedParsedConfig[configName] = v.Interface()
-			rv.Elem().Set(v.Elem())
-		} else {
 			return ErrNoConfigItemFound
 		}
+		parser := helper.fileFormat.decoder()
+		inf, err = applyDefaults(inf, ctyp, parser, helper.fileFormat.TagKey)
+		if err != nil {
+			return &ConfigError{Name: configName, Err: err}
+		}
+		inf = coerceValue(inf, ctyp, helper.fileFormat.TagKey)
+		b, _ := parser.Marshal(inf)
+		v := reflect.New(ctyp.Elem())
+		if err = parser.Unmarshal(b, v.Interface()); err != nil {
+			return &ConfigError{Name: configName, Err: err}
+		}
+		if err = validateConfig(configName, v.Interface(), helper.fileFormat.TagKey); err != nil {
+			return &ConfigError{Name: configName, Err: err}
+		}
+		helper.cachedParsedConfig[configName] = v.Interface()
+		rv.Elem().Set(v.Elem())
 	}
 	return nil
 }
+
+// 配置文件中的配置项，叠加上对应的环境变量，都不存在时返回nil
+func (helper *configHelper) entryOf(configName string, ctyp reflect.Type) (interface{}, error) {
+	inf, exists := helper.fileEntries[configName]
+	if !exists {
+		// .env等格式中的名称可能是大写的
+		key := matchKey(helper.fileEntries, configName)
+		inf, exists = helper.fileEntries[key]
+	}
+	var overrides []*envOverride
+	for _, o := range helper.envOverrides {
+		if o.matches(configName) {
+			overrides = append(overrides, o)
+		}
+	}
+	if len(overrides) == 0 {
+		if !exists {
+			return nil, nil
+		}
+		return inf, nil
+	}
+	// 复制一份，避免修改已加载的配置
+	section := make(map[string]interface{})
+	if m, ok := inf.(map[string]interface{}); ok {
+		mergeEntries(section, m)
+	}
+	if err := applyTypedEnvOverrides(section, ctyp, overrides, helper.fileFormat.TagKey); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+func (helper *configHelper) parsedCount() int {
+	helper.mu.Lock()
+	defer helper.mu.Unlock()
+	return len(helper.cachedParsedConfig)
+}
+
+// 已经解析过的配置项的类型
+func (helper *configHelper) parsedTypes() []reflect.Type {
+	helper.mu.Lock()
+	defer helper.mu.Unlock()
+	types := make([]reflect.Type, 0, len(helper.cachedParsedConfig))
+	for _, c := range helper.cachedParsedConfig {
+		types = append(types, reflect.TypeOf(c))
+	}
+	return types
+}
+
+func (helper *configHelper) allSettings() (map[string]interface{}, error) {
+	parser := helper.fileFormat.decoder()
+	b, err := parser.Marshal(helper.rawConfigEntries)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]interface{})
+	err = parser.Unmarshal(b, &settings)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
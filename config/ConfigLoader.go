@@ -1,39 +1,78 @@
 package config
 
 import (
-	"bytes"
-	"errors"
+	"flag"
+	"fmt"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-// 加载本地配置文件，配合环境变量CONFIG_PROFILE的值，来确定从那个文件中读取配置
-// 寻找路径形式为："./resource/app-[${CONFIG_PROFILE}].[yml|yaml|json]"
-// 如果没有设定 CONFIG_PROFILE，则默认使用 "./resource/app.[yml|yaml|json]"
-// 对于同一个CONFIG_PROFILE值，应当只存在一个对应的配置文件，如果存在不同后缀的配置文件，
-// 例如，同时存在 ./resource/app.yml, ./resource/app.yaml, ./resource/app.json三个文件
-// 则只会使用其中一个文件，这往往会产生令人疑惑的结果
-// 配置文件中可以使用类似 ${ENV_VAR} 的形式来表示，使用环境变量获取此值，如果没有设置，则默认为空
-// 具体匹配的正则表达式为： `\$\{\s*[a-zA-Z_][a-zA-Z0-9_]*\s*\}`
+// 分层加载本地配置文件，后加载的层覆盖先加载的层，优先级从低到高依次为：
+//
+//	1. ${dir}/app.${suffix}                       基础配置，suffix为任一已注册格式的后缀，例如yaml、json、toml
+//	2. ${dir}/app-${CONFIG_PROFILE}.${suffix}     环境配置，设置了CONFIG_PROFILE时才会加载
+//	3. ${dir}/app.local.${suffix}                 本地覆盖配置，一般不提交到代码仓库
+//	4. ${dir}/app-${CONFIG_PROFILE}.local.${suffix}
+//	5. 环境变量，例如 GIN__PORT=9090 覆盖 gin.port，详见EnvOverride.go
+//
+// 配置目录${dir}和文件名前缀app按如下优先级确定：
+//
+//	1. 命令行参数 -gostarter.config=<path>，path可以是配置文件或者配置目录
+//	2. 环境变量 CONFIG_FILE=<path>，指定基础配置文件，其他层在同一目录下按相同的文件名前缀查找
+//	3. 环境变量 CONFIG_DIR=<dir> 和 CONFIG_NAME=<name>，分别指定配置目录和文件名前缀
+//	4. 依次查找 ./resource/、可执行文件所在目录下的 resource/、可执行文件所在目录，使用第一个存在配置文件的目录
+//
+// 每一层都是可选的，但至少要存在一个配置文件。map类型的配置项会被逐层深度合并，其他类型的配置项直接被覆盖。
+// 同一层只应当存在一个配置文件，如果存在不同后缀的配置文件，例如同时存在 ./resource/app.yml 和 ./resource/app.json，
+// 则只会使用其中一个文件，并打印警告。
+// 配置文件中的字符串可以使用 ${ENV_VAR}、${ENV_VAR:-default}、${ENV_VAR:?message} 的形式引用环境变量，详见Placeholder.go
 
 const (
 	CONFIG_PROFILE = "CONFIG_PROFILE"
+	CONFIG_FILE    = "CONFIG_FILE"
+	CONFIG_DIR     = "CONFIG_DIR"
+	CONFIG_NAME    = "CONFIG_NAME"
+
+	// 指定配置文件或者配置目录的命令行参数名，使用前缀以免与应用自身的参数冲突
+	ConfigFlagName = "gostarter.config"
+
+	defaultConfigDir  = "./resource/"
+	defaultConfigName = "app"
 )
 
+// 将-gostarter.config参数注册到fs中，以便fs.Parse能够识别此参数，例如在main函数中：
+//
+//	config.RegisterFlag(flag.CommandLine)
+//	flag.Parse()
+//
+// 配置在init阶段就已经通过扫描os.Args加载，早于fs.Parse，这里注册的参数只用于通过解析和显示帮助信息
+func RegisterFlag(fs *flag.FlagSet) {
+	if fs.Lookup(ConfigFlagName) == nil {
+		fs.String(ConfigFlagName, "", "config file or directory")
+	}
+}
+
 type ConfigLoader interface {
-	load() ([]byte, *fileFormat, error)
+	load() ([]*configLayer, error)
+}
+
+// 一个配置文件解析后的内容
+type configLayer struct {
+	source  string // 配置文件路径
+	format  *FileFormat
+	entries map[string]interface{}
 }
 
 type localConfigLoader struct {
-	cfgFileDir     string
+	searchDirs     []string // 依次查找的配置目录，使用第一个存在配置文件的目录
+	baseFile       string   // 明确指定的基础配置文件，不为空时必须存在
 	fileNamePrefix string
 	confProfile    string
-	knownFormats   []*fileFormat
-	envVarsRegex   *regexp.Regexp
+	knownFormats   []*FileFormat
 }
 
 func newLocalConfigLoader() (*localConfigLoader, error) {
@@ -43,56 +82,202 @@ func newLocalConfigLoader() (*localConfigLoader, error) {
 		log.Warn().Msgf("environment variable \"CONFIG_PROFILE\" not set, app.yaml or app.json will be used")
 	}
 	confProfile = strings.ToLower(strings.TrimSpace(s))
-	reg, _ := regexp.Compile(`\$\{\s*[a-zA-Z_][a-zA-Z0-9_]*\s*\}`)
-	return &localConfigLoader{
-		cfgFileDir:     "./resource/",
-		fileNamePrefix: "app",
+	loader := &localConfigLoader{
+		fileNamePrefix: defaultConfigName,
 		confProfile:    confProfile,
-		knownFormats:   allSupportedFileFormats,
-		envVarsRegex:   reg,
-	}, nil
+		knownFormats:   knownFileFormats(),
+	}
+	if name := strings.TrimSpace(os.Getenv(CONFIG_NAME)); len(name) != 0 {
+		loader.fileNamePrefix = name
+	}
+	path := lookupFlag(os.Args[1:], ConfigFlagName)
+	if len(path) == 0 {
+		path = strings.TrimSpace(os.Getenv(CONFIG_FILE))
+	}
+	if len(path) != 0 {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			loader.searchDirs = []string{path}
+		} else {
+			loader.setBaseFile(path)
+		}
+	} else if dir := strings.TrimSpace(os.Getenv(CONFIG_DIR)); len(dir) != 0 {
+		loader.searchDirs = []string{dir}
+	} else {
+		loader.searchDirs = defaultSearchDirs()
+	}
+	return loader, nil
+}
+
+// 根据基础配置文件确定配置目录和文件名前缀，例如/etc/echo/echo.yaml对应/etc/echo/和echo
+func (loader *localConfigLoader) setBaseFile(path string) {
+	loader.baseFile = path
+	loader.searchDirs = []string{filepath.Dir(path)}
+	name := filepath.Base(path)
+	loader.fileNamePrefix = strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func defaultSearchDirs() []string {
+	dirs := []string{defaultConfigDir}
+	if exe, err := os.Executable(); err == nil {
+		exeDir := filepath.Dir(exe)
+		dirs = append(dirs, filepath.Join(exeDir, "resource"), exeDir)
+	}
+	return dirs
+}
+
+// 从命令行参数中找出指定参数的值，支持"-name value"、"-name=value"以及双横线的形式
+func lookupFlag(args []string, name string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if arg == name && i+1 < len(args) {
+			return strings.TrimSpace(args[i+1])
+		}
+		if v, ok := strings.CutPrefix(arg, name+"="); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// 按优先级从低到高排列的各层配置文件名，不含后缀
+func (loader *localConfigLoader) layerNames() []string {
+	names := []string{loader.fileNamePrefix}
+	if len(loader.confProfile) != 0 {
+		names = append(names, loader.fileNamePrefix+"-"+loader.confProfile)
+	}
+	names = append(names, loader.fileNamePrefix+".local")
+	if len(loader.confProfile) != 0 {
+		names = append(names, loader.fileNamePrefix+"-"+loader.confProfile+".local")
+	}
+	return names
 }
 
-func (loader *localConfigLoader) load() ([]byte, *fileFormat, error) {
+func (loader *localConfigLoader) load() ([]*configLayer, error) {
 	oldGlobalLevel := zerolog.GlobalLevel()
 	defer func() {
 		zerolog.SetGlobalLevel(oldGlobalLevel)
 	}()
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	var rawCfg []byte
-	var err error
-	formats := loader.knownFormats
-	var pathPrefix string
-	if len(loader.confProfile) == 0 {
-		pathPrefix = loader.cfgFileDir + loader.fileNamePrefix + "."
-	} else {
-		pathPrefix = loader.cfgFileDir + loader.fileNamePrefix + "-" + loader.confProfile + "."
+	for _, dir := range loader.searchDirs {
+		layers, err := loader.loadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(layers) != 0 {
+			return layers, nil
+		}
+		log.Trace().Msgf("no config file found in %s", dir)
 	}
+	return nil, ErrNoConfigFileFound
+}
 
-	var foundFormat *fileFormat = nil
-out:
-	for i := range formats {
-		for _, suffix := range formats[i].fileSuffix {
-			filepath := pathPrefix + suffix
-			rawCfg, err = os.ReadFile(filepath)
+func (loader *localConfigLoader) loadDir(dir string) ([]*configLayer, error) {
+	var layers []*configLayer
+	for i, name := range loader.layerNames() {
+		var layer *configLayer
+		var err error
+		if i == 0 && len(loader.baseFile) != 0 {
+			layer, err = loader.loadFile(loader.baseFile)
+		} else {
+			layer, err = loader.loadLayer(filepath.Join(dir, name) + ".")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if layer != nil {
+			log.Info().Msgf("load config file: %s", layer.source)
+			layers = append(layers, layer)
+		}
+	}
+	return layers, nil
+}
+
+// 加载一层配置文件，不存在时返回nil
+func (loader *localConfigLoader) loadLayer(pathPrefix string) (*configLayer, error) {
+	var layer *configLayer
+	for _, format := range loader.knownFormats {
+		for _, suffix := range format.Suffixes {
+			path := pathPrefix + suffix
+			if _, err := os.Stat(path); err != nil {
+				log.Trace().Err(err).Msgf("can't open config file: %s", path)
+				continue
+			}
+			if layer != nil {
+				log.Warn().Msgf("config file %s is ignored, because %s is used", path, layer.source)
+				continue
+			}
+			var err error
+			if layer, err = loader.loadFile(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return layer, nil
+}
+
+// 加载明确指定的配置文件，根据后缀确定文件格式
+func (loader *localConfigLoader) loadFile(path string) (*configLayer, error) {
+	suffix := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	for _, format := range loader.knownFormats {
+		for _, s := range format.Suffixes {
+			if s != suffix {
+				continue
+			}
+			rawCfg, err := os.ReadFile(path)
 			if err != nil {
-				log.Trace().Err(err).Msgf("can't open config file: %s", filepath)
-			} else {
-				foundFormat = formats[i]
-				break out
+				return nil, fmt.Errorf("%w: %v", ErrNoConfigFileFound, err)
+			}
+			entries := make(map[string]interface{})
+			if err = format.Parser.Unmarshal(rawCfg, &entries); err != nil {
+				return nil, &ConfigError{Err: fmt.Errorf("parse %s failed: %w", path, err)}
 			}
+			if err = resolvePlaceholders(entries); err != nil {
+				return nil, &ConfigError{Err: fmt.Errorf("resolve placeholders in %s failed: %w", path, err)}
+			}
+			return &configLayer{source: path, format: format, entries: entries}, nil
 		}
 	}
-	if foundFormat == nil {
-		return nil, nil, errors.New("no config file found")
+	return nil, &ConfigError{Err: fmt.Errorf("unsupported config file format: %s", path)}
+}
+
+// 所有可能被加载的配置文件路径，包括尚不存在的文件，用于监听配置文件的变化
+func (loader *localConfigLoader) candidatePaths() []string {
+	var paths []string
+	if len(loader.baseFile) != 0 {
+		paths = append(paths, loader.baseFile)
+	}
+	for _, dir := range loader.searchDirs {
+		for _, name := range loader.layerNames() {
+			for _, format := range loader.knownFormats {
+				for _, suffix := range format.Suffixes {
+					paths = append(paths, filepath.Join(dir, name)+"."+suffix)
+				}
+			}
+		}
 	}
-	// replace all env vars expression with their values
-	replacedRawCfg := loader.envVarsRegex.ReplaceAllFunc(rawCfg, func(b []byte) []byte {
-		val, set := os.LookupEnv(string(bytes.TrimSpace(b[2 : len(b)-1])))
-		if !set {
-			log.Warn().Msgf("env variable \"%s\" not set", string(bytes.TrimSpace(b[2:len(b)-1])))
+	return paths
+}
+
+// 将src深度合并到dst中，两边都是map的配置项逐层合并，其他情况以src为准
+func mergeEntries(dst, src map[string]interface{}) {
+	for k, sv := range src {
+		if sm, ok := sv.(map[string]interface{}); ok {
+			dm, ok := dst[k].(map[string]interface{})
+			if !ok {
+				// 复制一份，避免修改到被合并的配置层
+				dm = make(map[string]interface{}, len(sm))
+				dst[k] = dm
+			}
+			mergeEntries(dm, sm)
+			continue
 		}
-		return bytes.TrimSpace([]byte(val))
-	})
-	return replacedRawCfg, foundFormat, nil
+		dst[k] = sv
+	}
 }
@@ -1,12 +1,21 @@
 package config
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
 )
 
-type configParser interface {
+// 配置文件的解析器，可以通过RegisterFormat注册自定义的实现
+type ConfigParser interface {
 	Marshal(i interface{}) ([]byte, error)
 	Unmarshal(b []byte, i interface{}) error
 }
@@ -30,3 +39,152 @@ func (parser *jsonParser) Marshal(i interface{}) ([]byte, error) {
 func (parser *jsonParser) Unmarshal(b []byte, i interface{}) error {
 	return json.Unmarshal(b, i)
 }
+
+type tomlParser struct{}
+
+func (parser *tomlParser) Marshal(i interface{}) ([]byte, error) {
+	return toml.Marshal(i)
+}
+
+func (parser *tomlParser) Unmarshal(b []byte, i interface{}) error {
+	return toml.Unmarshal(b, i)
+}
+
+// .env格式，每行一个"KEY=VALUE"，使用"__"分隔各级名称，与环境变量覆盖配置的规则相同，例如：
+//
+//	# 注释
+//	GIN__PORT=8080
+//	export GORM__PRIMARY__DSN="root:root@tcp(127.0.0.1:3306)/app"
+//
+// 双引号中的值支持\n、\t、\"等转义，单引号中的值保持原样
+type dotenvParser struct{}
+
+func (parser *dotenvParser) Marshal(i interface{}) ([]byte, error) {
+	return marshalFlat(i, envKeySeparator, "=", strconv.Quote)
+}
+
+func (parser *dotenvParser) Unmarshal(b []byte, i interface{}) error {
+	return unmarshalFlat(b, i, envKeySeparator, func(line string) (string, string, error) {
+		line = strings.TrimPrefix(line, "export ")
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return "", "", fmt.Errorf("invalid line: %s", line)
+		}
+		val = strings.TrimSpace(val)
+		switch {
+		case len(val) >= 2 && val[0] == '"' && val[len(val)-1] == '"':
+			unquoted, err := strconv.Unquote(val)
+			if err != nil {
+				return "", "", fmt.Errorf("invalid value of %s: %w", key, err)
+			}
+			val = unquoted
+		case len(val) >= 2 && val[0] == '\'' && val[len(val)-1] == '\'':
+			val = val[1 : len(val)-1]
+		default:
+			// 未加引号的值中，" #"之后的内容为注释
+			if idx := strings.Index(val, " #"); idx >= 0 {
+				val = strings.TrimSpace(val[:idx])
+			}
+		}
+		return strings.TrimSpace(key), val, nil
+	})
+}
+
+// .properties格式，每行一个"a.b.c=value"或者"a.b.c: value"，使用"."分隔各级名称，
+// 以"#"或"!"开头的行为注释，行尾的"\"表示下一行是当前值的延续
+type propertiesParser struct{}
+
+func (parser *propertiesParser) Marshal(i interface{}) ([]byte, error) {
+	return marshalFlat(i, ".", "=", func(s string) string { return s })
+}
+
+func (parser *propertiesParser) Unmarshal(b []byte, i interface{}) error {
+	return unmarshalFlat(b, i, ".", func(line string) (string, string, error) {
+		idx := strings.IndexAny(line, "=:")
+		if idx < 0 {
+			return "", "", fmt.Errorf("invalid line: %s", line)
+		}
+		return strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:]), nil
+	})
+}
+
+// 将扁平的键值对解析为嵌套的map，只能解析到map[string]interface{}中
+func unmarshalFlat(b []byte, i interface{}, sep string, parseLine func(string) (string, string, error)) error {
+	m, ok := i.(*map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unsupported target type: %s", reflect.TypeOf(i))
+	}
+	if *m == nil {
+		*m = make(map[string]interface{})
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	var pending string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if pending != "" {
+			line = pending + line
+			pending = ""
+		}
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") && sep == "." {
+			pending = strings.TrimSuffix(line, "\\")
+			continue
+		}
+		key, val, err := parseLine(line)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return fmt.Errorf("invalid line: %s", line)
+		}
+		setFlatEntry(*m, strings.Split(key, sep), val)
+	}
+	if pending != "" {
+		key, val, err := parseLine(pending)
+		if err != nil {
+			return err
+		}
+		setFlatEntry(*m, strings.Split(key, sep), val)
+	}
+	return scanner.Err()
+}
+
+func setFlatEntry(m map[string]interface{}, path []string, val string) {
+	for _, p := range path[:len(path)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[p] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = val
+}
+
+// 将嵌套的map展开为扁平的键值对，按名称排序
+func marshalFlat(i interface{}, sep string, assign string, quote func(string) string) ([]byte, error) {
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unsupported source type: %s", reflect.TypeOf(i))
+	}
+	var lines []string
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		if sub, ok := v.(map[string]interface{}); ok {
+			for k, item := range sub {
+				key := k
+				if prefix != "" {
+					key = prefix + sep + k
+				}
+				walk(key, item)
+			}
+			return
+		}
+		lines = append(lines, prefix+assign+quote(fmt.Sprint(v)))
+	}
+	walk("", m)
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 配置项的默认值可以写在字段的default tag中，按照yaml的语法解析，切片写作"[a, b]"，例如：
+//
+//	type HttpServer struct {
+//		Port    uint16   `yaml:"port" json:"port" default:"8080"`
+//		Timeout string   `yaml:"timeout" json:"timeout" default:"30s"`
+//		Origins []string `yaml:"origins" json:"origins" default:"[*]"`
+//	}
+//
+// 也可以实现Defaulter接口，在Defaults方法中设置tag无法表达的默认值，同一字段两者都设置时以Defaults方法为准。
+// GetConfig在解析之前应用默认值，只有配置文件和环境变量中都不存在的配置项才会使用默认值，
+// 因此配置文件中明确写出的零值（例如debug: false）不会被覆盖。
+// 结构体字段、指针指向的结构体，以及切片和map中的结构体都会被应用默认值，但值为nil的指针字段不会被创建
+
+const defaultTagKey = "default"
+
+// 配置项可以实现此接口来设置默认值，Defaults方法在零值上调用
+type Defaulter interface {
+	Defaults()
+}
+
+// 为代码中构造的配置设置默认值，只会修改零值字段，c必须是一个指针。
+// 与GetConfig不同，这里无法区分明确设置的零值和缺省的配置项
+func SetDefaults(c interface{}) error {
+	rv := reflect.ValueOf(c)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("non-pointer param, type: %s", reflect.TypeOf(c))
+	}
+	return setDefaults(rv.Elem())
+}
+
+func setDefaults(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return setDefaults(v.Elem())
+	case reflect.Struct:
+		var defaults reflect.Value
+		if _, ok := reflect.New(v.Type()).Interface().(Defaulter); ok {
+			defaults = reflect.New(v.Type())
+			defaults.Interface().(Defaulter).Defaults()
+			defaults = defaults.Elem()
+		}
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.IsZero() && defaults.IsValid() {
+				fv.Set(defaults.Field(i))
+			}
+			if def, ok := f.Tag.Lookup(defaultTagKey); ok && fv.IsZero() {
+				if err := yaml.Unmarshal([]byte(def), fv.Addr().Interface()); err != nil {
+					return fmt.Errorf("invalid default value of %s.%s: %w", typ.Name(), f.Name, err)
+				}
+			}
+			if err := setDefaults(fv); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := setDefaults(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map中的值不可寻址，复制一份修改后写回
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := setDefaults(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+// 按照类型将默认值合并到配置条目中，返回合并后的副本，v中已存在的配置项优先
+func applyDefaults(v interface{}, typ reflect.Type, parser ConfigParser, tagKey string) (interface{}, error) {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return v, nil
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			if v != nil {
+				return v, nil
+			}
+			m = make(map[string]interface{})
+		}
+		merged := make(map[string]interface{}, len(m))
+		if _, ok := reflect.New(typ).Interface().(Defaulter); ok {
+			defaults, err := defaultsOf(typ, parser)
+			if err != nil {
+				return nil, err
+			}
+			mergeEntries(merged, defaults)
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get(tagKey), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			key, exists := lookupKey(m, name)
+			if exists {
+				item, err := applyDefaults(m[key], f.Type, parser, tagKey)
+				if err != nil {
+					return nil, err
+				}
+				// Defaults方法设置的嵌套配置项与已存在的配置项合并
+				if dm, ok := merged[name].(map[string]interface{}); ok {
+					if im, ok := item.(map[string]interface{}); ok {
+						mergeEntries(dm, im)
+						item = dm
+					}
+				}
+				delete(merged, name)
+				merged[key] = item
+				continue
+			}
+			// Defaults方法设置的值优先于tag
+			if def, ok := f.Tag.Lookup(defaultTagKey); ok && isZeroEntry(merged[name]) {
+				var item interface{}
+				if err := yaml.Unmarshal([]byte(def), &item); err != nil {
+					return nil, fmt.Errorf("invalid default value of %s.%s: %w", typ.Name(), f.Name, err)
+				}
+				merged[name] = item
+				continue
+			}
+			if f.Type.Kind() == reflect.Struct {
+				// 不存在的结构体字段中也可能有默认值
+				item, err := applyDefaults(merged[name], f.Type, parser, tagKey)
+				if err != nil {
+					return nil, err
+				}
+				if sub, ok := item.(map[string]interface{}); ok && len(sub) != 0 {
+					merged[name] = sub
+				}
+			}
+		}
+		// 保留结构体中不存在的配置项
+		for k, item := range m {
+			if _, ok := merged[k]; !ok {
+				merged[k] = item
+			}
+		}
+		return merged, nil
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+		merged := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			item, err := applyDefaults(item, typ.Elem(), parser, tagKey)
+			if err != nil {
+				return nil, err
+			}
+			merged[k] = item
+		}
+		return merged, nil
+	case reflect.Slice, reflect.Array:
+		l, ok := v.([]interface{})
+		if !ok {
+			return v, nil
+		}
+		merged := make([]interface{}, len(l))
+		for i, item := range l {
+			item, err := applyDefaults(item, typ.Elem(), parser, tagKey)
+			if err != nil {
+				return nil, err
+			}
+			merged[i] = item
+		}
+		return merged, nil
+	}
+	return v, nil
+}
+
+// Defaults方法设置的默认值，转换为配置条目
+func defaultsOf(typ reflect.Type, parser ConfigParser) (map[string]interface{}, error) {
+	c := reflect.New(typ)
+	c.Interface().(Defaulter).Defaults()
+	b, err := parser.Marshal(c.Interface())
+	if err != nil {
+		return nil, err
+	}
+	defaults := make(map[string]interface{})
+	if err = parser.Unmarshal(b, &defaults); err != nil {
+		return nil, err
+	}
+	return defaults, nil
+}
+
+func isZeroEntry(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	return reflect.ValueOf(v).IsZero()
+}
+
+// 不区分大小写地查找配置项名称
+func lookupKey(m map[string]interface{}, name string) (string, bool) {
+	key := matchKey(m, name)
+	_, ok := m[key]
+	return key, ok
+}
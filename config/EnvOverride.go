@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 通过环境变量覆盖配置项，环境变量名使用双下划线"__"分隔配置项的各级名称，
+// 名称不区分大小写，例如：
+//
+//	GIN__PORT=9090                        覆盖 gin.port
+//	GORM__PRIMARY__DSN=...                覆盖 gorm.primary.dsn
+//	GORM__PRIMARY__MAXOPENCONNS=20        覆盖 gorm.primary.maxOpenConns
+//	GIN__CORS__ORIGINS=a.com,b.com        覆盖 gin.cors.origins，切片使用逗号分隔，也可以写成[a.com, b.com]
+//
+// 配置项名称中的"."、"-"、"/"和"~"在环境变量名中写作"_"，例如 GO_REDIS__CLIENTS__REDIS_1__CONN_URL。
+// GetConfig时，环境变量的值按照配置结构体中字段的类型转换，名称按照字段的tag匹配，
+// 因此配置文件中不存在的配置项同样可以被覆盖。不包含"__"的环境变量会被忽略
+
+const envKeySeparator = "__"
+
+var envNameReplacer = regexp.MustCompile(`[./~-]`)
+
+// 一个覆盖配置项的环境变量
+type envOverride struct {
+	name  string   // 环境变量名
+	path  []string // 按"__"分隔后的各级名称
+	value string
+}
+
+func parseEnvOverrides(environ []string) []*envOverride {
+	var overrides []*envOverride
+	for _, kv := range environ {
+		name, val, ok := strings.Cut(kv, "=")
+		if !ok || !strings.Contains(name, envKeySeparator) {
+			continue
+		}
+		path := strings.Split(name, envKeySeparator)
+		valid := true
+		for _, p := range path {
+			if p == "" {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			overrides = append(overrides, &envOverride{name: name, path: path, value: val})
+		}
+	}
+	return overrides
+}
+
+func loadEnvOverrides() []*envOverride {
+	return parseEnvOverrides(os.Environ())
+}
+
+// 环境变量的第一级名称是否对应configName
+func (o *envOverride) matches(configName string) bool {
+	return strings.EqualFold(o.path[0], envNameReplacer.ReplaceAllString(configName, "_"))
+}
+
+// 不考虑类型，将环境变量覆盖到entries中，用于AllSettings。
+// 只覆盖配置文件中已存在的配置项，避免与配置无关的环境变量（例如其他服务的密钥）出现在AllSettings中
+func applyEnvOverrides(entries map[string]interface{}, overrides []*envOverride) {
+	for _, o := range overrides {
+		key, exists := lookupKey(entries, o.path[0])
+		if !exists {
+			continue
+		}
+		section, ok := entries[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// 类型未知，按照yaml标量解析
+		_ = setEntry(section, nil, o.path[1:], o.value, "")
+	}
+}
+
+// 按路径设置配置项。typ为配置项对应的类型，不为nil时按照其字段的tag确定名称并转换值的类型，
+// 否则不区分大小写地匹配已有的名称，不存在时使用小写名称
+func setEntry(m map[string]interface{}, typ reflect.Type, path []string, raw string, tagKey string) error {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	key, fieldTyp := matchField(m, typ, path[0], tagKey)
+	if len(path) == 1 {
+		val, err := convertEnvValue(raw, fieldTyp)
+		if err != nil {
+			return err
+		}
+		m[key] = val
+		return nil
+	}
+	next, ok := m[key].(map[string]interface{})
+	if !ok {
+		next = make(map[string]interface{})
+		m[key] = next
+	}
+	return setEntry(next, fieldTyp, path[1:], raw, tagKey)
+}
+
+// 根据类型确定名称对应的配置项名和类型
+func matchField(m map[string]interface{}, typ reflect.Type, name string, tagKey string) (string, reflect.Type) {
+	if typ != nil {
+		switch typ.Kind() {
+		case reflect.Struct:
+			for i := 0; i < typ.NumField(); i++ {
+				f := typ.Field(i)
+				if !f.IsExported() {
+					continue
+				}
+				tagName, _, _ := strings.Cut(f.Tag.Get(tagKey), ",")
+				if tagName == "-" {
+					continue
+				}
+				if tagName == "" {
+					tagName = f.Name
+				}
+				if strings.EqualFold(tagName, name) || strings.EqualFold(envNameReplacer.ReplaceAllString(tagName, "_"), name) {
+					return tagName, f.Type
+				}
+			}
+		case reflect.Map:
+			return matchKey(m, name), typ.Elem()
+		}
+	}
+	return matchKey(m, name), nil
+}
+
+func matchKey(m map[string]interface{}, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) || strings.EqualFold(envNameReplacer.ReplaceAllString(k, "_"), name) {
+			return k
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// 按照类型转换环境变量的值，类型未知时按照yaml标量解析
+func convertEnvValue(raw string, typ reflect.Type) (interface{}, error) {
+	if typ == nil {
+		return parseEnvValue(raw), nil
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	raw = strings.TrimSpace(raw)
+	switch typ.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, err := strconv.ParseInt(raw, 10, typ.Bits()); err == nil {
+			return v, nil
+		}
+		// 例如time.Duration，交给类型自身的解析方式处理
+		return parseEnvValue(raw), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseUint(raw, 10, typ.Bits()); err == nil {
+			return v, nil
+		}
+		// 例如ByteSize，交给类型自身的解析方式处理
+		if _, ok := reflect.New(typ).Interface().(yaml.Unmarshaler); ok {
+			return raw, nil
+		}
+		return strconv.ParseUint(raw, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, typ.Bits())
+	case reflect.Slice, reflect.Array:
+		if strings.HasPrefix(raw, "[") {
+			var l []interface{}
+			if err := yaml.Unmarshal([]byte(raw), &l); err != nil {
+				return nil, err
+			}
+			return l, nil
+		}
+		l := make([]interface{}, 0)
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			v, err := convertEnvValue(item, typ.Elem())
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		return l, nil
+	}
+	return parseEnvValue(raw), nil
+}
+
+func parseEnvValue(s string) interface{} {
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil || v == nil {
+		return s
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		// 只解析标量，避免"a: b"这样的值被解析为map
+		return s
+	}
+	// 避免"0123"这样的值被解析为八进制数字，无法原样还原的值保留字符串
+	if fmt.Sprint(v) != strings.TrimSpace(s) {
+		return s
+	}
+	return v
+}
+
+// 将环境变量按照c的类型覆盖到section中
+func applyTypedEnvOverrides(section map[string]interface{}, typ reflect.Type, overrides []*envOverride, tagKey string) error {
+	for _, o := range overrides {
+		if err := setEntry(section, typ, o.path[1:], o.value, tagKey); err != nil {
+			return fmt.Errorf("invalid value of env %s: %w", o.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMalformedConfigName = errors.New("malformed config name")
+	ErrNoConfigItemFound   = errors.New("no config item found")
+	ErrNoConfigFileFound   = errors.New("no config file found")
+)
+
+// 加载或者解析配置失败时返回的错误，可以通过errors.As获取出错的配置项名称。
+// 为了兼容已有的判断方式，ErrNoConfigItemFound不会被包装
+type ConfigError struct {
+	Name string // 配置项名称，配置文件加载失败时为空
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("config: %v", e.Err)
+	}
+	return fmt.Sprintf("config %s: %v", e.Name, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
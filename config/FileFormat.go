@@ -1,30 +1,100 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
 // 定义可以处理的文件格式
 
-type fileFormat struct {
-	name           string
-	fileSuffix     []string
-	fieldTagPrefix string
-	parser         configParser
+// 一种配置文件格式
+type FileFormat struct {
+	Name     string
+	Suffixes []string     // 文件后缀，不含"."，例如"yaml"
+	TagKey   string       // 将配置项解析到结构体时使用的tag，例如"yaml"
+	Parser   ConfigParser // 将配置文件的内容解析为map[string]interface{}
+	Decoder  ConfigParser // 将配置项解析到结构体中，为nil时使用Parser
+}
+
+func (f *FileFormat) decoder() ConfigParser {
+	if f.Decoder != nil {
+		return f.Decoder
+	}
+	return f.Parser
 }
 
 var (
-	jsonFormat = &fileFormat{
-		name:           "JSON",
-		fileSuffix:     []string{"json"},
-		fieldTagPrefix: "json",
-		parser:         &jsonParser{},
+	jsonFormat = &FileFormat{
+		Name:     "JSON",
+		Suffixes: []string{"json"},
+		TagKey:   "json",
+		Parser:   &jsonParser{},
+	}
+	yamlFormat = &FileFormat{
+		Name:     "YAML",
+		Suffixes: []string{"yml", "yaml"},
+		TagKey:   "yaml",
+		Parser:   &yamlParser{},
+	}
+	// 配置结构体普遍只有yaml和json的tag，因此以下格式都使用yaml tag来解析配置项
+	tomlFormat = &FileFormat{
+		Name:     "TOML",
+		Suffixes: []string{"toml"},
+		TagKey:   "yaml",
+		Parser:   &tomlParser{},
+		Decoder:  &yamlParser{},
 	}
-	yamlFormat = &fileFormat{
-		name:           "YAML",
-		fileSuffix:     []string{"yml", "yaml"},
-		fieldTagPrefix: "yaml",
-		parser:         &yamlParser{},
+	dotenvFormat = &FileFormat{
+		Name:     "DOTENV",
+		Suffixes: []string{"env"},
+		TagKey:   "yaml",
+		Parser:   &dotenvParser{},
+		Decoder:  &yamlParser{},
+	}
+	propertiesFormat = &FileFormat{
+		Name:     "PROPERTIES",
+		Suffixes: []string{"properties"},
+		TagKey:   "yaml",
+		Parser:   &propertiesParser{},
+		Decoder:  &yamlParser{},
 	}
 
-	allSupportedFileFormats = []*fileFormat{
+	formatsMu               sync.Mutex
+	allSupportedFileFormats = []*FileFormat{
 		jsonFormat,
 		yamlFormat,
+		tomlFormat,
+		dotenvFormat,
+		propertiesFormat,
 	}
 )
+
+// 注册一种配置文件格式，例如HCL。注册之后会重新加载默认配置，
+// 但在此之前通过GetConfig读取的配置不会更新，因此应当尽早注册，例如在main包的init中
+func RegisterFormat(f *FileFormat) error {
+	if f == nil || f.Parser == nil || len(f.Suffixes) == 0 {
+		return fmt.Errorf("invalid file format: parser and suffixes are required")
+	}
+	formatsMu.Lock()
+	for _, suffix := range f.Suffixes {
+		for _, known := range allSupportedFileFormats {
+			for _, s := range known.Suffixes {
+				if strings.EqualFold(s, suffix) {
+					formatsMu.Unlock()
+					return fmt.Errorf("file suffix %s is already registered by %s", suffix, known.Name)
+				}
+			}
+		}
+	}
+	allSupportedFileFormats = append(allSupportedFileFormats, f)
+	formatsMu.Unlock()
+	reloadDefaultHelper()
+	return nil
+}
+
+func knownFileFormats() []*FileFormat {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	return append([]*FileFormat{}, allSupportedFileFormats...)
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 配置文件中的字符串可以引用环境变量，支持以下形式：
+//
+//	${NAME}            环境变量的值，没有设置时为空字符串并打印警告
+//	${NAME:-default}   环境变量没有设置或者为空时使用default
+//	${NAME:?message}   环境变量没有设置或者为空时加载配置失败，message为错误信息，适用于必须提供的密钥
+//	$${NAME}           转义，得到原样的"${NAME}"
+//
+// 替换在配置文件解析之后进行，只作用于字符串类型的值，不作用于配置项名称，
+// 因此环境变量中的":"、"#"等yaml特殊字符不会破坏配置文件的结构。
+// 替换后的字符串在GetConfig时按照字段的类型转换，例如 port: ${PORT} 可以解析到uint16类型的字段中
+
+var placeholderRegexp = regexp.MustCompile(`\$?\$\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(?::([-?])([^}]*))?\}`)
+
+// 替换配置中所有字符串里的占位符，返回所有必须提供但没有设置的环境变量对应的错误
+func resolvePlaceholders(entries map[string]interface{}) error {
+	var errs []error
+	for k, v := range entries {
+		entries[k] = resolveValue(v, &errs)
+	}
+	return errors.Join(errs...)
+}
+
+func resolveValue(v interface{}, errs *[]error) interface{} {
+	switch val := v.(type) {
+	case string:
+		return resolveString(val, errs)
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = resolveValue(item, errs)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = resolveValue(val[i], errs)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func resolveString(s string, errs *[]error) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return placeholderRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+		sub := placeholderRegexp.FindStringSubmatch(m)
+		name, op, arg := sub[1], sub[2], sub[3]
+		val, set := os.LookupEnv(name)
+		val = strings.TrimSpace(val)
+		switch op {
+		case "-":
+			if val == "" {
+				return arg
+			}
+		case "?":
+			if val == "" {
+				msg := strings.TrimSpace(arg)
+				if msg == "" {
+					msg = "required but not set"
+				}
+				*errs = append(*errs, fmt.Errorf("env variable %s: %s", name, msg))
+			}
+		default:
+			if !set {
+				log.Warn().Msgf("env variable \"%s\" not set", name)
+			}
+		}
+		return val
+	})
+}
+
+// 按照配置结构体的字段类型，将字符串转换为数字、布尔值等类型，其他值保持不变。
+// 结构体字段的名称不区分大小写地匹配，并统一为tag中的名称，例如.env文件中的PORT对应port。
+// 返回转换后的副本，不会修改v
+func coerceValue(v interface{}, typ reflect.Type, tagKey string) interface{} {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return v
+	}
+	switch val := v.(type) {
+	case string:
+		switch typ.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if converted, err := convertEnvValue(val, typ); err == nil {
+				return converted
+			}
+		}
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct, reflect.Map:
+			m := make(map[string]interface{}, len(val))
+			for k, item := range val {
+				key, fieldTyp := matchField(val, typ, k, tagKey)
+				m[key] = coerceValue(item, fieldTyp, tagKey)
+			}
+			return m
+		}
+	case []interface{}:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			l := make([]interface{}, len(val))
+			for i := range val {
+				l[i] = coerceValue(val[i], typ.Elem(), tagKey)
+			}
+			return l
+		}
+	}
+	return v
+}
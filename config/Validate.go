@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetConfig解析配置项之后会对其进行校验，校验失败时返回*ConfigError，其中包含所有*ValidationError。
+// 校验规则写在字段的validate tag中，多个规则使用逗号分隔，例如：
+//
+//	type HttpServer struct {
+//		Port uint16 `yaml:"port" json:"port" validate:"required,min=1024"`
+//		Mode string `yaml:"mode" json:"mode" validate:"oneof=debug release"`
+//		Addr string `yaml:"addr" json:"addr" validate:"omitempty,url"`
+//	}
+//
+// 支持的规则：
+//
+//	required      不能为零值，指针不能为nil，字符串、切片和map不能为空
+//	omitempty     为零值时跳过其余规则
+//	min=n, max=n  数字的取值范围，字符串、切片和map的长度范围，time.Duration和Duration可以写作min=1s，ByteSize可以写作max=1MiB
+//	oneof=a b c   取值只能是其中之一，使用空格分隔
+//	url           带有scheme和host的url
+//	duration      可以被time.ParseDuration解析的字符串
+//
+// 结构体、指针、切片和map中的结构体会被递归校验。
+// 此外，实现了Validator接口的配置项及其中的结构体字段（包括map中的值），会在规则校验之后调用Validate方法
+
+const validateTagKey = "validate"
+
+// 配置项可以实现此接口，进行tag无法描述的校验，例如字段之间的约束
+type Validator interface {
+	Validate() error
+}
+
+// 一个字段校验失败的原因，Path为字段在配置文件中的路径，例如gin.port、gorm.primary.dsn
+type ValidationError struct {
+	Path string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	durationType       = reflect.TypeOf(time.Duration(0))
+	configDurationType = reflect.TypeOf(Duration(0))
+	byteSizeType       = reflect.TypeOf(ByteSize(0))
+)
+
+// 校验配置项，返回的错误包含所有校验失败的字段
+func validateConfig(configName string, c interface{}, tagKey string) error {
+	var errs []error
+	validateValue(reflect.ValueOf(c), configName, tagKey, &errs)
+	return errors.Join(errs...)
+}
+
+func validateValue(v reflect.Value, path string, tagKey string, errs *[]error) {
+	if !v.IsValid() {
+		return
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if !v.IsNil() {
+			validateValue(v.Elem(), path, tagKey, errs)
+		}
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get(tagKey), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fieldPath := path + "." + name
+			fv := v.Field(i)
+			if rules := strings.TrimSpace(f.Tag.Get(validateTagKey)); rules != "" {
+				if err := checkRules(fv, rules); err != nil {
+					*errs = append(*errs, &ValidationError{Path: fieldPath, Err: err})
+					continue
+				}
+			}
+			validateValue(fv, fieldPath, tagKey, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), tagKey, errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(), fmt.Sprintf("%s.%v", path, iter.Key()), tagKey, errs)
+		}
+	}
+	// 优先通过地址调用，以支持指针接收者的Validate方法。map中的值无法取地址，复制一份再调用
+	if !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+	v = v.Addr()
+	if validator, ok := v.Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			*errs = append(*errs, &ValidationError{Path: path, Err: err})
+		}
+	}
+}
+
+func checkRules(v reflect.Value, rules string) error {
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+		case "omitempty":
+			if isEmpty(v) {
+				return nil
+			}
+		case "required":
+			if isEmpty(v) {
+				return errors.New("is required")
+			}
+		default:
+			// 其余规则作用于指针指向的值，nil指针跳过
+			for v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return nil
+				}
+				v = v.Elem()
+			}
+			if err := checkRule(v, name, param); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func checkRule(v reflect.Value, name, param string) error {
+	switch name {
+	case "min", "max":
+		return checkRange(v, name, param)
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(param) {
+			if s == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of [%s], got %q", param, s)
+	case "url":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("rule url can't be applied to %s", v.Type())
+		}
+		u, err := url.Parse(v.String())
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid url %q", v.String())
+		}
+	case "duration":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("rule duration can't be applied to %s", v.Type())
+		}
+		if _, err := time.ParseDuration(v.String()); err != nil {
+			return fmt.Errorf("invalid duration %q", v.String())
+		}
+	default:
+		return fmt.Errorf("unknown validation rule: %s", name)
+	}
+	return nil
+}
+
+func checkRange(v reflect.Value, name, param string) error {
+	var actual, limit float64
+	var err error
+	subject, got := "value", v.Interface()
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		subject, got = "length", v.Len()
+		actual = float64(v.Len())
+		limit, err = strconv.ParseFloat(param, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType || v.Type() == configDurationType {
+			var d time.Duration
+			if d, err = time.ParseDuration(param); err == nil {
+				actual, limit = float64(v.Int()), float64(d)
+			}
+		} else {
+			actual = float64(v.Int())
+			limit, err = strconv.ParseFloat(param, 64)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(v.Uint())
+		if v.Type() == byteSizeType {
+			var size ByteSize
+			size, err = ParseByteSize(param)
+			limit = float64(size)
+		} else {
+			limit, err = strconv.ParseFloat(param, 64)
+		}
+	case reflect.Float32, reflect.Float64:
+		actual = v.Float()
+		limit, err = strconv.ParseFloat(param, 64)
+	default:
+		return fmt.Errorf("rule %s can't be applied to %s", name, v.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid param of rule %s: %s", name, param)
+	}
+	if name == "min" && actual < limit {
+		return fmt.Errorf("%s must be at least %s, got %v", subject, param, got)
+	}
+	if name == "max" && actual > limit {
+		return fmt.Errorf("%s must be at most %s, got %v", subject, param, got)
+	}
+	return nil
+}
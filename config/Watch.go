@@ -0,0 +1,217 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 监听配置文件的变化，配置文件被修改、创建或删除时重新加载全部配置：
+//  1. 重新读取各层配置文件和环境变量，生成新的配置快照
+//  2. 使用新快照解析并校验所有被订阅的配置项，以及已经通过GetConfig读取过的配置项，任一配置项出错时放弃本次加载，继续使用原有配置
+//  3. 替换原有配置，此后GetConfig返回新的配置
+//  4. 通知内容发生变化的订阅者
+//
+// 通过轮询文件的修改时间和大小发现变化，第一次调用Watch时开始轮询，默认每2s一次，例如：
+//
+//	cancel, err := config.Watch(&ginConf{}, func(old, new config.Configurable) {
+//		cfg := new.(*ginConf)
+//		// apply cfg
+//	})
+
+var defaultWatchInterval = 2 * time.Second
+
+var (
+	watchMu       sync.Mutex
+	subscriptions = make(map[int]*subscription)
+	nextSubId     int
+	watchInterval = defaultWatchInterval
+	stopPolling   chan struct{} // 轮询开始之后才不为nil
+
+	reloadMu sync.Mutex // 保证同一时刻只有一次重新加载
+)
+
+type subscription struct {
+	typ     reflect.Type
+	fn      func(old, new Configurable)
+	current Configurable
+}
+
+// 设置轮询配置文件的间隔，在下一次开始轮询时生效
+func SetWatchInterval(d time.Duration) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	if d > 0 {
+		watchInterval = d
+	}
+}
+
+// 订阅一项配置的变化，c必须是一个指针，仅用于确定配置项的名称和类型。
+// 配置项的内容变化时调用fn，old和new与c的类型相同，fn中不应调用Reload。
+// 配置文件加载失败时同样会订阅，此时old为默认配置，配置文件被修复之后通知订阅者。
+// 返回的函数用于取消订阅，所有订阅都被取消之后停止轮询
+func Watch(c Configurable, fn func(old, new Configurable)) (func(), error) {
+	if c == nil {
+		return nil, errors.New("watched config can't be nil")
+	}
+	if fn == nil {
+		return nil, errors.New("watch func can't be nil")
+	}
+	typ := reflect.TypeOf(c)
+	if typ.Kind() != reflect.Pointer {
+		return nil, &ConfigError{Name: c.ConfigName(), Err: fmt.Errorf("non-pointer param, type: %s", typ)}
+	}
+	current := reflect.New(typ.Elem()).Interface().(Configurable)
+	if helper, _ := currentHelper(); helper == nil {
+		if err := SetDefaults(current); err != nil {
+			return nil, &ConfigError{Name: current.ConfigName(), Err: err}
+		}
+	} else if err := helper.getConfig(current); err != nil && !errors.Is(err, ErrNoConfigItemFound) {
+		return nil, err
+	}
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	id := nextSubId
+	nextSubId++
+	subscriptions[id] = &subscription{typ: typ, fn: fn, current: current}
+	if stopPolling == nil {
+		stopPolling = make(chan struct{})
+		go poll(stopPolling, watchInterval)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			watchMu.Lock()
+			defer watchMu.Unlock()
+			delete(subscriptions, id)
+			if len(subscriptions) == 0 && stopPolling != nil {
+				close(stopPolling)
+				stopPolling = nil
+			}
+		})
+	}, nil
+}
+
+func poll(stop chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var failed string         // 上一次加载失败时配置文件的状态，文件没有再次变化时不重复加载
+	var initialPaths []string // 首次加载失败时需要监听的配置文件
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			var signature string
+			if helper, _ := currentHelper(); helper != nil {
+				signature = fileSignature(helper.watchPaths)
+				if signature == helper.signature {
+					continue
+				}
+			} else {
+				if initialPaths == nil {
+					l, err := newLocalConfigLoader()
+					if err != nil {
+						continue
+					}
+					initialPaths = l.candidatePaths()
+				}
+				signature = fileSignature(initialPaths)
+			}
+			if signature == failed {
+				continue
+			}
+			log.Info().Msg("config file changed, reloading")
+			if err := Reload(); err != nil {
+				failed = signature
+				log.Error().Err(err).Msg("reload config failed, keep the loaded config")
+			}
+		}
+	}
+}
+
+// 立即重新加载配置，可以在收到SIGHUP等信号时调用。
+// 加载或校验失败时返回错误，并继续使用原有配置
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	ch, err := newConfigHelper()
+	if err != nil {
+		return err
+	}
+	watchMu.Lock()
+	subs := make([]*subscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		subs = append(subs, sub)
+	}
+	watchMu.Unlock()
+
+	type change struct {
+		sub      *subscription
+		old, new Configurable
+	}
+	// 已经读取过的配置项同样需要通过校验，避免重新加载成功之后再读取时出错
+	var types []reflect.Type
+	if old, _ := currentHelper(); old != nil {
+		types = old.parsedTypes()
+	}
+	for _, sub := range subs {
+		types = append(types, sub.typ)
+	}
+	parsed := make(map[reflect.Type]Configurable)
+	var errs []error
+	for _, typ := range types {
+		if _, exists := parsed[typ]; exists {
+			continue
+		}
+		c := reflect.New(typ.Elem()).Interface().(Configurable)
+		if err := ch.getConfig(c); err != nil && !errors.Is(err, ErrNoConfigItemFound) {
+			errs = append(errs, err)
+		}
+		parsed[typ] = c
+	}
+	var changes []change
+	for _, sub := range subs {
+		if c := parsed[sub.typ]; !reflect.DeepEqual(sub.current, c) {
+			changes = append(changes, change{sub: sub, old: sub.current, new: c})
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	swapHelper(ch)
+	log.Info().Msg("config reloaded")
+	for _, c := range changes {
+		c.sub.current = c.new
+		notify(c.sub, c.old, c.new)
+	}
+	return nil
+}
+
+func notify(sub *subscription, old, new Configurable) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Msgf("config watch func of %s panic: %v", new.ConfigName(), r)
+		}
+	}()
+	sub.fn(old, new)
+}
+
+// 配置文件的修改时间和大小，文件不存在时同样记录，以便发现新建的文件
+func fileSignature(paths []string) string {
+	var sb strings.Builder
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&sb, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+		} else {
+			fmt.Fprintf(&sb, "%s:-;", path)
+		}
+	}
+	return sb.String()
+}
@@ -1,14 +1,37 @@
 // 用于加载应用的配置。
 //
-// 支持的配置文件格式为yaml和json，相应支持的文件后缀为yml，yaml，json。
+// 支持的配置文件格式为yaml、json、toml、.env和.properties，相应支持的文件后缀为yml，yaml，json，toml，env，properties。
+// toml、.env和.properties格式的配置项按照结构体的yaml tag解析；.env使用"__"分隔各级名称，例如 GIN__PORT=8080，
+// .properties使用"."分隔各级名称，例如 gin.port=8080。
+// 其他格式（例如HCL）可以实现 ConfigParser 接口，通过 RegisterFormat 注册。
 //
-// 配置文件的查找路径为当前工作目录下的：resource/。
+// 配置文件的默认查找路径为当前工作目录下的 resource/，其次是可执行文件所在目录下的 resource/ 和可执行文件所在目录。
+// 也可以通过命令行参数 -gostarter.config=<文件或目录>（需要使用 RegisterFlag 注册到应用的 FlagSet）、环境变量 CONFIG_FILE、CONFIG_DIR 和 CONFIG_NAME 指定配置文件的位置。
 //
-// 您可以为不同的开发环境配置不同的配置文件，配合环境变量 CONF_PROFILE 来指定特定场景使用的配置文件。
+// 您可以为不同的开发环境配置不同的配置文件，配合环境变量 CONFIG_PROFILE 来指定特定场景使用的配置文件。
 //
-// 比如, CONF_PROFILE=prod, 则会使用 resource/app-prod.yaml 或者 resource/app-prod.json.
+// 比如, CONFIG_PROFILE=prod, 则会在 resource/app.yaml 的基础上叠加 resource/app-prod.yaml 或者 resource/app-prod.json.
 //
-// 如果没有指定 CONF_PROFILE 环境变量，则会使用 resource/app.yaml 或者 resource/app.json。
+// 如果没有指定 CONFIG_PROFILE 环境变量，则只会使用 resource/app.yaml 或者 resource/app.json。
+//
+// 配置按层加载并深度合并，优先级从低到高为：
+// app.yaml、app-${CONFIG_PROFILE}.yaml、app.local.yaml、app-${CONFIG_PROFILE}.local.yaml、环境变量。
+// 每一层都是可选的，例如 app-prod.yaml 中只需要写出与 app.yaml 不同的配置项；
+// 环境变量使用双下划线分隔各级名称，例如 GIN__PORT=9090 覆盖 gin.port。
+//
+// 配置文件中的字符串可以引用环境变量：${NAME}、${NAME:-default}、${NAME:?message}，使用 $${NAME} 表示原样的文本。
+//
+// 字段的默认值可以写在 default tag 中，例如 `default:"8080"`，也可以实现 Defaulter 接口，
+// 只有配置文件和环境变量中都不存在的配置项才会使用默认值。代码中构造的配置可以使用 SetDefaults 设置默认值。
+//
+// 时长和字节数可以使用 Duration 和 ByteSize 类型，配置文件中写作 30s、5m、64KiB 等形式，yaml 和 json 中的写法相同。
+//
+// GetConfig 会按照字段的 validate tag（required、min、max、oneof、url、duration 等）校验配置项，
+// 并调用实现了 Validator 接口的 Validate 方法，校验失败时返回包含字段路径的错误，例如 gin.port: is required。
+//
+// 通过 Watch 订阅配置项的变化，配置文件被修改后会重新加载、校验并通知订阅者，校验失败时继续使用原有配置；
+// 也可以调用 Reload 手动重新加载。只有调用了 Watch 才会轮询配置文件，重新加载时会校验被订阅的以及已经读取过的全部配置项。
+// zerologstarter 在配置了 zerolog.watch: true 时订阅 zerolog.globalLevel，修改后无需重启即可生效。
 //
 // 可以使用如下方式快速为您的应用增加一项配置，下面的示例展示了如何为
 //
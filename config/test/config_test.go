@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/why2go/gostarter/config"
 )
 
@@ -78,3 +79,16 @@ func TestConfig(t *testing.T) {
 	}
 	fmt.Printf("app2: %v\n", app2)
 }
+
+func TestLayeredConfig(t *testing.T) {
+	appCfg := &App{}
+	assert.Nil(t, config.GetConfig(appCfg))
+	// app_name和author来自app.json，version被app.local.yaml覆盖
+	assert.Equal(t, "demo app", appCfg.AppName)
+	assert.Equal(t, "ziyi", appCfg.Author)
+	assert.Equal(t, "v0.0.3", appCfg.Version)
+
+	srv := &Server{}
+	assert.Nil(t, config.GetConfig(srv))
+	assert.Equal(t, []string{"10.0.0.1:8081", "10.0.0.2:9090"}, srv.Addresses)
+}
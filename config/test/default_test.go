@@ -0,0 +1,51 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+type DefaultedServer struct {
+	Port      uint16                        `yaml:"port" json:"port" default:"8080"`
+	Debug     bool                          `yaml:"debug" json:"debug" default:"true"`
+	Mode      string                        `yaml:"mode" json:"mode" default:"release"`
+	Origins   []string                      `yaml:"origins" json:"origins"`
+	Upstreams map[string]*DefaultedUpstream `yaml:"upstreams" json:"upstreams"`
+}
+
+func (DefaultedServer) ConfigName() string {
+	return "defaults"
+}
+
+func (s *DefaultedServer) Defaults() {
+	s.Origins = []string{"*"}
+}
+
+type DefaultedUpstream struct {
+	Weight int `yaml:"weight" json:"weight" default:"10"`
+}
+
+type MissingServer DefaultedServer
+
+func (MissingServer) ConfigName() string {
+	return "missing"
+}
+
+func TestDefaults(t *testing.T) {
+	srv := &DefaultedServer{}
+	assert.Nil(t, config.GetConfig(srv))
+	assert.Equal(t, uint16(9090), srv.Port)
+	assert.False(t, srv.Debug) // 明确写出的零值不会被覆盖
+	assert.Equal(t, "release", srv.Mode)
+	assert.Equal(t, []string{"*"}, srv.Origins)
+	assert.Equal(t, 10, srv.Upstreams["primary"].Weight)
+
+	// 配置项不存在时同样设置默认值
+	missing := &MissingServer{}
+	assert.True(t, errors.Is(config.GetConfig(missing), config.ErrNoConfigItemFound))
+	assert.Equal(t, uint16(8080), missing.Port)
+	assert.True(t, missing.Debug)
+}
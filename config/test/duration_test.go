@@ -0,0 +1,45 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+	"gopkg.in/yaml.v3"
+)
+
+type Limits struct {
+	Timeout     config.Duration `yaml:"timeout" json:"timeout"`
+	IdleTimeout config.Duration `yaml:"idleTimeout" json:"idleTimeout" default:"5m"`
+	Buffer      config.ByteSize `yaml:"buffer" json:"buffer" validate:"max=1MiB"`
+	MaxBody     config.ByteSize `yaml:"maxBody" json:"maxBody"`
+}
+
+func (Limits) ConfigName() string {
+	return "limits"
+}
+
+func TestDurationAndByteSize(t *testing.T) {
+	limits := &Limits{}
+	assert.Nil(t, config.GetConfig(limits))
+	assert.Equal(t, 90*time.Second, limits.Timeout.Std())
+	assert.Equal(t, 5*time.Minute, limits.IdleTimeout.Std())
+	assert.Equal(t, 64*config.KiB, limits.Buffer)
+	assert.Equal(t, config.ByteSize(4096), limits.MaxBody)
+
+	var fromYaml Limits
+	assert.Nil(t, yaml.Unmarshal([]byte("timeout: 500ms\nbuffer: 1.5MiB\nmaxBody: 2KB"), &fromYaml))
+	assert.Equal(t, 500*time.Millisecond, fromYaml.Timeout.Std())
+	assert.Equal(t, config.ByteSize(1536*1024), fromYaml.Buffer)
+	assert.Equal(t, config.ByteSize(2000), fromYaml.MaxBody)
+	assert.NotNil(t, yaml.Unmarshal([]byte("timeout: 30"), &fromYaml)) // 缺少单位
+
+	b, err := json.Marshal(limits)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"timeout":"1m30s","idleTimeout":"5m0s","buffer":"64KiB","maxBody":"4KiB"}`, string(b))
+	var fromJson Limits
+	assert.Nil(t, json.Unmarshal(b, &fromJson))
+	assert.Equal(t, *limits, fromJson)
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+type HttpServer struct {
+	Port     uint16   `yaml:"port" json:"port"`
+	MaxConns int      `yaml:"maxConns" json:"maxConns"`
+	Debug    bool     `yaml:"debug" json:"debug"`
+	Origins  []string `yaml:"origins" json:"origins"`
+}
+
+func (HttpServer) ConfigName() string {
+	return "httpServer"
+}
+
+func TestEnvOverride(t *testing.T) {
+	if os.Getenv("ENV_OVERRIDE_CHILD") == "1" {
+		appCfg := &App{}
+		assert.Nil(t, config.GetConfig(appCfg))
+		assert.Equal(t, "demo app", appCfg.AppName)
+		assert.Equal(t, "0123", appCfg.Version) // 按字段类型转换，不会被解析为数字
+
+		srv := &Server{}
+		assert.Nil(t, config.GetConfig(srv))
+		assert.Equal(t, []string{"10.0.0.3:80", "10.0.0.4:80"}, srv.Addresses)
+
+		// 配置文件中不存在的配置项
+		httpSrv := &HttpServer{}
+		assert.Nil(t, config.GetConfig(httpSrv))
+		assert.Equal(t, HttpServer{Port: 9090, MaxConns: 20, Debug: true, Origins: []string{"a.com"}}, *httpSrv)
+
+		settings, err := config.AllSettings()
+		assert.Nil(t, err)
+		assert.Equal(t, "0123", settings["app"].(map[string]interface{})["version"])
+		// 与配置文件无关的环境变量不会出现在AllSettings中
+		assert.NotContains(t, settings, "payment")
+		assert.NotContains(t, settings, "PAYMENT")
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestEnvOverride$")
+	cmd.Env = append(os.Environ(), "ENV_OVERRIDE_CHILD=1",
+		"APP__VERSION=0123",
+		"SERVER__ADDRESSES=10.0.0.3:80, 10.0.0.4:80",
+		"HTTPSERVER__PORT=9090",
+		"HTTPSERVER__MAXCONNS=20",
+		"httpserver__debug=true",
+		"HTTPSERVER__ORIGINS=[a.com]",
+		"PAYMENT__API_KEY=sk_live_abc",
+	)
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
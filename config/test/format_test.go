@@ -0,0 +1,53 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+func TestFileFormat(t *testing.T) {
+	if os.Getenv("CONFIG_FORMAT_CHILD") == "1" {
+		appCfg := &App{}
+		assert.Nil(t, config.GetConfig(appCfg))
+		assert.Equal(t, "format app", appCfg.AppName)
+		assert.Equal(t, "v2.0.0", appCfg.Version)
+		server := &HttpServer{}
+		assert.Nil(t, config.GetConfig(server))
+		assert.Equal(t, uint16(8080), server.Port)
+		assert.Equal(t, 100, server.MaxConns)
+		assert.True(t, server.Debug)
+		return
+	}
+	for _, file := range []string{"alt/format.toml", "alt/format.env", "alt/format.properties"} {
+		t.Run(file, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestFileFormat$")
+			cmd.Env = append(os.Environ(), "CONFIG_FORMAT_CHILD=1", "CONFIG_FILE="+file)
+			out, err := cmd.CombinedOutput()
+			assert.Nil(t, err, string(out))
+		})
+	}
+}
+
+type jsonParser struct{}
+
+func (jsonParser) Marshal(i interface{}) ([]byte, error) {
+	return json.Marshal(i)
+}
+
+func (jsonParser) Unmarshal(b []byte, i interface{}) error {
+	return json.Unmarshal(b, i)
+}
+
+func TestRegisterFormat(t *testing.T) {
+	assert.NotNil(t, config.RegisterFormat(&config.FileFormat{Name: "JSON5", Suffixes: []string{"json5"}}))
+	assert.NotNil(t, config.RegisterFormat(&config.FileFormat{Name: "YAML2", Suffixes: []string{"YAML"}, Parser: jsonParser{}}))
+	assert.Nil(t, config.RegisterFormat(&config.FileFormat{Name: "JSON5", Suffixes: []string{"json5"}, TagKey: "json", Parser: jsonParser{}}))
+	// 已加载的配置不受影响
+	appCfg := &App{}
+	assert.Nil(t, config.GetConfig(appCfg))
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+func init() {
+	// 测试程序会解析命令行参数，需要注册配置文件参数
+	config.RegisterFlag(flag.CommandLine)
+}
+
+// 配置在init阶段加载，需要在子进程中验证环境变量和命令行参数的效果
+func TestConfigLocation(t *testing.T) {
+	if os.Getenv("CONFIG_LOCATION_CHILD") == "1" {
+		appCfg := &App{}
+		assert.Nil(t, config.GetConfig(appCfg))
+		assert.Equal(t, "custom app", appCfg.AppName)
+		assert.Equal(t, "v1.0.0", appCfg.Version)
+		return
+	}
+	cases := map[string]struct {
+		env  []string
+		args []string
+	}{
+		"ConfigFileEnv": {env: []string{"CONFIG_FILE=alt/custom.yaml"}},
+		"ConfigDirEnv":  {env: []string{"CONFIG_DIR=alt", "CONFIG_NAME=custom"}},
+		"ConfigFlag":    {args: []string{"-gostarter.config=alt/custom.yaml"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], append([]string{"-test.run=^TestConfigLocation$"}, c.args...)...)
+			cmd.Env = append(append(os.Environ(), "CONFIG_LOCATION_CHILD=1"), c.env...)
+			out, err := cmd.CombinedOutput()
+			assert.Nil(t, err, string(out))
+		})
+	}
+}
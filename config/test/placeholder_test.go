@@ -0,0 +1,50 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+type Placeholder struct {
+	Port    uint16 `yaml:"port" json:"port"`
+	Dsn     string `yaml:"dsn" json:"dsn"`
+	Host    string `yaml:"host" json:"host"`
+	Literal string `yaml:"literal" json:"literal"`
+	Secret  string `yaml:"secret" json:"secret"`
+}
+
+func (Placeholder) ConfigName() string {
+	return "placeholder"
+}
+
+func TestPlaceholder(t *testing.T) {
+	switch os.Getenv("PLACEHOLDER_CHILD") {
+	case "ok":
+		cfg := &Placeholder{}
+		assert.Nil(t, config.GetConfig(cfg))
+		assert.Equal(t, Placeholder{
+			Port:    9090,
+			Dsn:     "user:p#ss@tcp(db: 3306)/app",
+			Host:    "localhost",
+			Literal: "${NOT_REPLACED}",
+			Secret:  "007",
+		}, *cfg)
+		return
+	case "missing":
+		err := config.GetConfig(&Placeholder{})
+		assert.ErrorContains(t, err, "PH_SECRET: secret is required")
+		return
+	}
+	run := func(child string, env ...string) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPlaceholder$")
+		cmd.Env = append(append(os.Environ(), "PLACEHOLDER_CHILD="+child, "CONFIG_FILE=alt/placeholder.yaml"), env...)
+		out, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+	run("ok", "PH_PORT=9090", "PH_DSN=user:p#ss@tcp(db: 3306)/app", "PH_SECRET=007")
+	run("missing", "PH_PORT=9090")
+}
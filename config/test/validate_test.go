@@ -0,0 +1,57 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+type ValidatedServer struct {
+	Port      uint16               `yaml:"port" json:"port" validate:"required"`
+	Mode      string               `yaml:"mode" json:"mode" validate:"oneof=debug release"`
+	Timeout   string               `yaml:"timeout" json:"timeout" validate:"omitempty,duration"`
+	Upstreams map[string]*Upstream `yaml:"upstreams" json:"upstreams" validate:"min=1"`
+	Fallbacks map[string]Upstream  `yaml:"fallbacks" json:"fallbacks"`
+}
+
+func (ValidatedServer) ConfigName() string {
+	return "validation"
+}
+
+type Upstream struct {
+	URL    string `yaml:"url" json:"url" validate:"url"`
+	Weight int    `yaml:"weight" json:"weight" validate:"min=0,max=100"`
+}
+
+func (u *Upstream) Validate() error {
+	if u.Weight == 0 {
+		return errors.New("weight is zero")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	err := config.GetConfig(&ValidatedServer{})
+	assert.NotNil(t, err)
+	var cfgErr *config.ConfigError
+	assert.True(t, errors.As(err, &cfgErr))
+	for _, path := range []string{
+		"validation.port",
+		"validation.mode",
+		"validation.timeout",
+		"validation.upstreams.primary.url",
+		"validation.upstreams.primary.weight",
+		// Validate方法返回的错误，路径为实现了Validator的值
+		"validation.upstreams.backup",
+		"validation.fallbacks.local",
+	} {
+		assert.Contains(t, err.Error(), path+":")
+	}
+	var validationErr *config.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "validation.port", validationErr.Path)
+	assert.Contains(t, err.Error(), "validation.upstreams.backup: weight is zero")
+	assert.Contains(t, err.Error(), "validation.fallbacks.local: weight is zero")
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/config"
+)
+
+type Watched struct {
+	Level   string   `yaml:"level" json:"level" validate:"oneof=debug info warn"`
+	Origins []string `yaml:"origins" json:"origins"`
+}
+
+func (Watched) ConfigName() string {
+	return "watched"
+}
+
+type Unwatched struct {
+	Port int `yaml:"port" json:"port" validate:"min=1"`
+}
+
+func (Unwatched) ConfigName() string {
+	return "unwatched"
+}
+
+// 配置目录在init阶段确定，需要在子进程中使用临时目录
+func TestWatch(t *testing.T) {
+	if dir := os.Getenv("CONFIG_WATCH_CHILD"); dir != "" {
+		file := filepath.Join(dir, "app.yaml")
+		config.SetWatchInterval(20 * time.Millisecond)
+		changes := make(chan *Watched, 1)
+		cancel, err := config.Watch(&Watched{}, func(old, new config.Configurable) {
+			assert.Equal(t, "info", old.(*Watched).Level)
+			changes <- new.(*Watched)
+		})
+		assert.Nil(t, err)
+		defer cancel()
+
+		// 校验失败时继续使用原有配置
+		assert.Nil(t, os.WriteFile(file, []byte("watched:\n  level: verbose\n"), 0644))
+		assert.NotNil(t, config.Reload())
+		cfg := &Watched{}
+		assert.Nil(t, config.GetConfig(cfg))
+		assert.Equal(t, "info", cfg.Level)
+
+		// 已经读取过的配置项即使没有被订阅，也要通过校验
+		assert.Nil(t, config.GetConfig(&Unwatched{}))
+		assert.Nil(t, os.WriteFile(file, []byte("watched:\n  level: info\nunwatched:\n  port: 0\n"), 0644))
+		assert.NotNil(t, config.Reload())
+		unwatched := &Unwatched{}
+		assert.Nil(t, config.GetConfig(unwatched))
+		assert.Equal(t, 8080, unwatched.Port)
+
+		assert.Nil(t, os.WriteFile(file, []byte("watched:\n  level: debug\n  origins: [a.com]\n"), 0644))
+		select {
+		case changed := <-changes:
+			assert.Equal(t, &Watched{Level: "debug", Origins: []string{"a.com"}}, changed)
+		case <-time.After(5 * time.Second):
+			t.Fatal("config change not notified")
+		}
+		assert.Nil(t, config.GetConfig(cfg))
+		assert.Equal(t, "debug", cfg.Level)
+		return
+	}
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("watched:\n  level: info\nunwatched:\n  port: 8080\n"), 0644))
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWatch$")
+	cmd.Env = append(os.Environ(), "CONFIG_WATCH_CHILD="+dir, "CONFIG_DIR="+dir)
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
+
+func TestWatchNil(t *testing.T) {
+	_, err := config.Watch(nil, func(old, new config.Configurable) {})
+	assert.NotNil(t, err)
+}
+
+// 首次加载失败时，配置文件被修复之后同样能够通知订阅者
+func TestWatchBrokenFile(t *testing.T) {
+	if dir := os.Getenv("CONFIG_WATCH_BROKEN_CHILD"); dir != "" {
+		config.SetWatchInterval(20 * time.Millisecond)
+		assert.NotNil(t, config.GetConfig(&Watched{}))
+		changes := make(chan *Watched, 1)
+		cancel, err := config.Watch(&Watched{}, func(old, new config.Configurable) {
+			assert.Equal(t, &Watched{}, old)
+			changes <- new.(*Watched)
+		})
+		assert.Nil(t, err)
+		defer cancel()
+
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("watched:\n  level: warn\n"), 0644))
+		select {
+		case changed := <-changes:
+			assert.Equal(t, "warn", changed.Level)
+		case <-time.After(5 * time.Second):
+			t.Fatal("fixed config file not loaded")
+		}
+		cfg := &Watched{}
+		assert.Nil(t, config.GetConfig(cfg))
+		assert.Equal(t, "warn", cfg.Level)
+		return
+	}
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("watched: [\n"), 0644))
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWatchBrokenFile$")
+	cmd.Env = append(os.Environ(), "CONFIG_WATCH_BROKEN_CHILD="+dir, "CONFIG_DIR="+dir)
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
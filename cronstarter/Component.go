@@ -0,0 +1,35 @@
+package cronstarter
+
+import (
+	"context"
+
+	"github.com/why2go/gostarter/boot"
+)
+
+const componentName = "cron"
+
+type cronComponent struct{}
+
+// 返回定时任务对应的boot组件，启动时开始调度，清理时停止调度并等待正在执行的任务结束：
+//
+//	boot.Use(cronstarter.Component())
+func Component() boot.Component {
+	return &cronComponent{}
+}
+
+func (*cronComponent) Name() string {
+	return componentName
+}
+
+// 任务一般会用到数据源，在数据源之后启动，在数据源之前清理
+func (*cronComponent) After() []string {
+	return []string{"gorm", "mongo", "redis", "kafka"}
+}
+
+func (*cronComponent) Start(ctx context.Context) error {
+	return start()
+}
+
+func (*cronComponent) Stop(ctx context.Context) error {
+	return stop(ctx)
+}
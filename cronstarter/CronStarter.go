@@ -0,0 +1,234 @@
+package cronstarter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/why2go/gostarter/config"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	logger = log.With().Str("ltag", "cronStarter").Logger()
+
+	// 支持标准的5段cron表达式，以及"@every 1m"、"@daily"等描述符
+	specParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+	mu        sync.Mutex
+	jobs      = make(map[string]*job)
+	scheduler *cron.Cron // 启动之后才不为nil
+
+	// 调度期间所有任务共用的ctx，清理超时时取消，停止调度之后为nil
+	jobCtx    context.Context
+	cancelJob context.CancelFunc
+)
+
+var (
+	ErrDuplicateJob = errors.New("duplicate cron job")
+	ErrJobNotFound  = errors.New("cron job not found")
+)
+
+type cronConf struct {
+	Location string              `yaml:"location" json:"location"` // 解析cron表达式使用的时区，默认为本地时区
+	Jobs     map[string]*jobConf `yaml:"jobs" json:"jobs"`
+}
+
+func (cronConf) ConfigName() string {
+	return "cron"
+}
+
+// 单个任务的配置，会覆盖注册时的设置
+type jobConf struct {
+	Spec     string          `yaml:"spec" json:"spec"`
+	Timeout  config.Duration `yaml:"timeout" json:"timeout"`
+	Disabled bool            `yaml:"disabled" json:"disabled"`
+}
+
+type JobOption func(*job)
+
+// 设置单次执行的时限，超时后取消任务的ctx
+func WithTimeout(d time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+type job struct {
+	name     string
+	spec     string
+	fn       func(ctx context.Context) error
+	timeout  time.Duration
+	disabled bool
+	running  atomic.Bool
+}
+
+// 注册一个定时任务，spec为标准的cron表达式或者"@every 10s"形式的间隔。
+// 上一次执行还没有结束时，本次执行会被跳过
+func Register(name string, spec string, fn func(ctx context.Context) error, opts ...JobOption) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("cron job name can't be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("cron job %s has no function", name)
+	}
+	j := &job{name: name, spec: strings.TrimSpace(spec), fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if _, err := specParser.Parse(j.spec); err != nil {
+		return fmt.Errorf("invalid spec of cron job %s: %w", name, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := jobs[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	jobs[name] = j
+	// 启动之后注册的任务直接加入调度
+	if scheduler != nil {
+		if err := schedule(jobCtx, scheduler, j); err != nil {
+			delete(jobs, name)
+			return err
+		}
+	}
+	return nil
+}
+
+// 立即执行一次指定的任务，同样遵循时限和防重叠的规则。
+// 调度期间使用与定时执行相同的ctx，启动之前或停止之后使用context.Background()
+func Trigger(name string) error {
+	mu.Lock()
+	j, ok := jobs[name]
+	ctx := jobCtx
+	mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	j.run(ctx)
+	return nil
+}
+
+func schedule(ctx context.Context, c *cron.Cron, j *job) error {
+	if j.disabled {
+		logger.Info().Str("job", j.name).Msg("cron job is disabled")
+		return nil
+	}
+	sched, err := specParser.Parse(j.spec)
+	if err != nil {
+		return fmt.Errorf("invalid spec of cron job %s: %w", j.name, err)
+	}
+	c.Schedule(sched, cron.FuncJob(func() { j.run(ctx) }))
+	logger.Info().Str("job", j.name).Str("spec", j.spec).Msg("cron job is scheduled")
+	return nil
+}
+
+func (j *job) run(ctx context.Context) {
+	if !j.running.CompareAndSwap(false, true) {
+		logger.Warn().Str("job", j.name).Msg("cron job is still running, skip")
+		return
+	}
+	defer j.running.Store(false)
+	if j.timeout > 0 {
+		var cf context.CancelFunc
+		ctx, cf = context.WithTimeout(ctx, j.timeout)
+		defer cf()
+	}
+	begin := time.Now()
+	err := j.safeRun(ctx)
+	elapsed := time.Since(begin)
+	if err != nil {
+		logger.Error().Err(err).Str("job", j.name).Dur("elapsed", elapsed).Msg("cron job failed")
+		return
+	}
+	logger.Info().Str("job", j.name).Dur("elapsed", elapsed).Msg("cron job finished")
+}
+
+func (j *job) safeRun(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.fn(ctx)
+}
+
+// 读取cron配置项，开始调度所有已注册的任务
+func start() error {
+	cfg := &cronConf{}
+	err := config.GetConfig(cfg)
+	if err != nil && !errors.Is(err, config.ErrNoConfigItemFound) {
+		return fmt.Errorf("load cron config failed: %w", err)
+	}
+	loc := time.Local
+	if len(cfg.Location) != 0 {
+		if loc, err = time.LoadLocation(cfg.Location); err != nil {
+			return fmt.Errorf("invalid cron location: %w", err)
+		}
+	}
+	for name, jc := range cfg.Jobs {
+		if jc == nil {
+			return &config.ConfigError{Name: cfg.ConfigName(), Err: fmt.Errorf("job %s is empty", name)}
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if scheduler != nil {
+		return nil
+	}
+	for name, jc := range cfg.Jobs {
+		j, ok := jobs[name]
+		if !ok {
+			logger.Warn().Str("job", name).Msg("cron job is configured but not registered")
+			continue
+		}
+		if len(jc.Spec) != 0 {
+			j.spec = strings.TrimSpace(jc.Spec)
+		}
+		if jc.Timeout != 0 {
+			j.timeout = jc.Timeout.Std()
+		}
+		j.disabled = jc.Disabled
+	}
+	c := cron.New(cron.WithParser(specParser), cron.WithLocation(loc))
+	jobCtx, cancelJob = context.WithCancel(context.Background())
+	for _, j := range jobs {
+		if err := schedule(jobCtx, c, j); err != nil {
+			cancelJob()
+			return err
+		}
+	}
+	c.Start()
+	scheduler = c
+	return nil
+}
+
+// 停止调度，等待正在执行的任务结束，ctx结束时取消这些任务
+func stop(ctx context.Context) error {
+	mu.Lock()
+	c, cf := scheduler, cancelJob
+	scheduler, jobCtx, cancelJob = nil, nil, nil
+	mu.Unlock()
+	if c == nil {
+		return nil
+	}
+	defer cf()
+	select {
+	case <-c.Stop().Done():
+		logger.Info().Msg("cron scheduler is stopped")
+		return nil
+	case <-ctx.Done():
+		logger.Warn().Msg("cron jobs did not finish in time, cancel them")
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,19 @@
+// 定时任务，通过cronstarter.Register注册任务，通过cron配置项覆盖任务的设置：
+/*
+	cron:
+	  location: Asia/Shanghai # 可选，默认为本地时区
+	  jobs:
+	    cleanup:
+	      spec: "0 3 * * *"   # 可选，覆盖注册时的表达式
+	      timeout: 10m        # 可选，单次执行的时限
+	      disabled: false     # 可选，禁用任务
+*/
+//
+// 支持标准的5段cron表达式，以及"@every 1m30s"、"@hourly"、"@daily"等描述符：
+//
+//	cronstarter.Register("cleanup", "@every 1h", cleanup, cronstarter.WithTimeout(10*time.Minute))
+//	boot.Use(cronstarter.Component())
+//
+// 上一次执行还没有结束时，本次执行会被跳过。每次执行的耗时和结果都会记录在日志中。
+// 应用清理时停止调度，并等待正在执行的任务结束，超出清理时限则取消任务的ctx。
+package cronstarter
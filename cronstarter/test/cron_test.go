@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/cronstarter"
+)
+
+func TestCron(t *testing.T) {
+	var ticks, disabledRuns atomic.Int32
+	// 配置文件中的spec会覆盖这里的表达式
+	assert.Nil(t, cronstarter.Register("tick", "0 0 1 1 *", func(context.Context) error {
+		ticks.Add(1)
+		return nil
+	}))
+	assert.Nil(t, cronstarter.Register("disabled", "@every 1s", func(context.Context) error {
+		disabledRuns.Add(1)
+		return nil
+	}))
+	assert.True(t, errors.Is(cronstarter.Register("tick", "@every 1s", func(context.Context) error { return nil }),
+		cronstarter.ErrDuplicateJob))
+	assert.NotNil(t, cronstarter.Register("invalid", "every second", func(context.Context) error { return nil }))
+
+	var slowRuns atomic.Int32
+	started := make(chan struct{})
+	assert.Nil(t, cronstarter.Register("slow", "@daily", func(ctx context.Context) error {
+		slowRuns.Add(1)
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	c := cronstarter.Component()
+	assert.Nil(t, c.Start(context.Background()))
+
+	t.Run("Schedule", func(t *testing.T) {
+		assert.Eventually(t, func() bool { return ticks.Load() > 0 }, 3*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(0), disabledRuns.Load())
+	})
+
+	t.Run("OverlapAndTimeout", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, cronstarter.Trigger("slow"))
+		}()
+		<-started
+		// 上一次执行还没有结束，本次被跳过
+		assert.Nil(t, cronstarter.Trigger("slow"))
+		wg.Wait() // 超时后ctx被取消
+		assert.Equal(t, int32(1), slowRuns.Load())
+		assert.True(t, errors.Is(cronstarter.Trigger("unknown"), cronstarter.ErrJobNotFound))
+	})
+
+	assert.Nil(t, c.Stop(context.Background()))
+
+	// 停止调度之后手动执行，ctx不应该已经被取消
+	var probeErr error
+	assert.Nil(t, cronstarter.Register("probe", "@daily", func(ctx context.Context) error {
+		probeErr = ctx.Err()
+		return nil
+	}))
+	assert.Nil(t, cronstarter.Trigger("probe"))
+	assert.Nil(t, probeErr)
+}
@@ -0,0 +1,35 @@
+package ginstarter
+
+import (
+	"context"
+
+	"github.com/why2go/gostarter/boot"
+)
+
+const componentName = "gin"
+
+type ginComponent struct{}
+
+// 返回http server对应的boot组件，启动和清理自动配对：
+//
+//	boot.Use(ginstarter.Component())
+func Component() boot.Component {
+	return &ginComponent{}
+}
+
+func (*ginComponent) Name() string {
+	return componentName
+}
+
+// 在数据源之后启动，在数据源之前清理
+func (*ginComponent) After() []string {
+	return []string{"gorm", "mongo", "redis", "kafka"}
+}
+
+func (*ginComponent) Start(ctx context.Context) error {
+	return httpServer.start()
+}
+
+func (*ginComponent) Stop(ctx context.Context) error {
+	return httpServer.stop(ctx)
+}
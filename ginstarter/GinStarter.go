@@ -2,11 +2,15 @@ package ginstarter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/why2go/gostarter/boot"
 	"github.com/why2go/gostarter/config"
 	ginlogger "github.com/why2go/gostarter/ginstarter/logger"
 
@@ -19,6 +23,7 @@ var (
 	DefaultRouter *gin.Engine
 	logger        = log.With().Str("ltag", "ginStarter").Logger()
 	httpServer    *ginServer
+	initErr       error // 加载配置时出现的错误，启动http server时返回
 
 	defaultListenPort      = uint16(8080)
 	defaultShutdownLatency = 5 * time.Minute
@@ -28,24 +33,34 @@ func init() {
 	cfg := &ginConf{}
 	err := config.GetConfig(cfg)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("load gin conf failed")
-		return
+		// 使用默认配置创建路由，保证注册路由的代码可以正常执行，错误在启动时返回
+		logger.Err(err).Msg("load gin conf failed")
+		initErr = fmt.Errorf("load gin conf failed: %w", err)
+		cfg = &ginConf{}
+		_ = config.SetDefaults(cfg)
 	}
 	router := newGinRouter(cfg)
 	DefaultRouter = router
 	gs := newGinServer(router, cfg.Host, cfg.Port)
 	httpServer = gs
+	boot.AddHealthChecker("gin", func(context.Context) error {
+		if !httpServer.serving.Load() {
+			return errors.New("http server is not serving")
+		}
+		return nil
+	})
 }
 
 type ginServer struct {
-	host   string
-	port   string
-	server *http.Server
+	host    string
+	port    string
+	server  *http.Server
+	serving atomic.Bool
 }
 
 type ginConf struct {
 	Host   string      `yaml:"host" json:"host"`
-	Port   uint16      `yaml:"port" json:"port"`
+	Port   uint16      `yaml:"port" json:"port" default:"8080"`
 	Mode   string      `yaml:"mode" json:"mode"`
 	Cors   *corsConf   `yaml:"cors" json:"cors"`
 	Logger *loggerConf `yaml:"logger" json:"logger"`
@@ -115,36 +130,62 @@ func setGinLogger(router *gin.Engine, loggerConf *loggerConf) {
 }
 
 func newGinServer(mux http.Handler, host string, port uint16) *ginServer {
+	// 与之前的版本保持一致，port为0时同样使用8080，而不是随机端口
+	if port == 0 {
+		port = defaultListenPort
+	}
 	svr := &ginServer{
-		host: "",
-		port: "8080",
+		host: host,
+		port: fmt.Sprint(port),
 		server: &http.Server{
 			Handler: mux,
 		},
 	}
-	if port == 0 {
-		port = defaultListenPort
-	}
 	svr.server.Addr = fmt.Sprintf("%s:%d", host, port)
 	return svr
 }
 
-func StartHttpServer() {
+func StartHttpServer() error {
+	if err := httpServer.start(); err != nil {
+		logger.Err(err).Msgf("http server listen failed")
+		return err
+	}
+	return nil
+}
+
+func StopHttpServer() {
+	ctx, cf := context.WithTimeout(context.Background(), defaultShutdownLatency)
+	defer cf()
+	if err := httpServer.stop(ctx); err != nil {
+		logger.Err(err).Msg("http server shutdown error")
+	}
+}
+
+// 先完成端口监听，以便在启动阶段就能发现端口占用等错误，再在后台处理请求
+func (gs *ginServer) start() error {
+	if initErr != nil {
+		return initErr
+	}
+	lis, err := net.Listen("tcp", gs.server.Addr)
+	if err != nil {
+		return err
+	}
+	gs.serving.Store(true)
 	go func() {
-		if err := httpServer.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Err(err).Msgf("http server listen failed")
+		defer gs.serving.Store(false)
+		if err := gs.server.Serve(lis); err != nil && err != http.ErrServerClosed {
+			logger.Err(err).Msgf("http server serve failed")
 		}
 	}()
+	return nil
 }
 
-func StopHttpServer() {
+func (gs *ginServer) stop(ctx context.Context) error {
 	logger.Info().Msg("shutting down http server...")
-	ctx, cf := context.WithTimeout(context.Background(), defaultShutdownLatency)
-	defer cf()
-	err := httpServer.server.Shutdown(ctx)
+	err := gs.server.Shutdown(ctx)
 	if err != nil {
-		logger.Err(err).Msg("http server shutdown error")
-		return
+		return err
 	}
 	logger.Info().Msg("http server is closed")
+	return nil
 }
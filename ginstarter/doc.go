@@ -2,7 +2,7 @@
 //
 //	gin:
 //	  host: "localhost"
-//	  port: 8080
+//	  port: 8080 # 默认8080，为0时同样使用8080
 //	  mode: release # debug
 //	  cors:
 //	    origins: ["*"]
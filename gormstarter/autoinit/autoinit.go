@@ -0,0 +1,21 @@
+// 导入此包，在init阶段读取配置并连接所有数据源，失败时退出进程：
+//
+//	import _ "github.com/why2go/gostarter/gormstarter/autoinit"
+package autoinit
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/why2go/gostarter/boot"
+	"github.com/why2go/gostarter/gormstarter"
+)
+
+func init() {
+	if err := gormstarter.Init(context.Background()); err != nil {
+		log.Fatal().Str("ltag", "gormStarter").Err(err).Msg("init gorm failed")
+	}
+	for name, checker := range gormstarter.HealthCheckers() {
+		boot.AddHealthChecker(name, checker)
+	}
+}
@@ -0,0 +1,53 @@
+package gormstarter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/why2go/gostarter/boot"
+)
+
+const componentName = "gorm"
+
+type gormComponent struct{}
+
+// 返回所有数据源对应的boot组件，启动时调用Init连接数据源，清理时关闭连接，启动后向应用提供各个连接的健康检查：
+//
+//	boot.Use(gormstarter.Component())
+func Component() boot.Component {
+	return &gormComponent{}
+}
+
+func (*gormComponent) Name() string {
+	return componentName
+}
+
+func (*gormComponent) Start(ctx context.Context) error {
+	return Init(ctx)
+}
+
+func (*gormComponent) HealthCheckers() map[string]boot.HealthChecker {
+	return HealthCheckers()
+}
+
+// 关闭所有数据源并清空，之后再次启动时重新连接
+func (*gormComponent) Stop(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	var errs []error
+	for srcName, db := range dataSourceMap {
+		delete(dataSourceMap, srcName)
+		sqlDb, err := db.DB()
+		if err == nil {
+			err = sqlDb.Close()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("close data source %s failed: %w", srcName, err))
+			continue
+		}
+		logger.Info().Msgf("data source %s is closed", srcName)
+	}
+	initialized = false
+	return errors.Join(errs...)
+}
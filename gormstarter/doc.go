@@ -12,6 +12,17 @@
 		  logger:
 		    logMode: info
 		    ignoreErrRecordNotFound: true
-		    slowThresholdMS: 200
+		    slowThreshold: 200ms # 默认200ms，旧的slowThresholdMS仍然有效
+		  retry: # 可选，连接失败时的重试策略，详见boot.RetryPolicy
+		    maxAttempts: 5
+		    initialInterval: 1s
+		    deadline: 30s
 */
+//
+// 导入本包不会读取配置或建立连接，需要通过以下任一方式显式初始化：
+//   - boot.Use(gormstarter.Component())：在应用启动时初始化，清理时关闭连接
+//   - gormstarter.Init(ctx)：手动初始化
+//   - import _ "github.com/why2go/gostarter/gormstarter/autoinit"：在init阶段初始化，失败时退出进程
+//
+// 初始化之后，使用gormstarter.GetDbBySourceName("db0")获取客户端。
 package gormstarter
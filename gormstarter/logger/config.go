@@ -4,13 +4,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/why2go/gostarter/config"
 	"gorm.io/gorm/logger"
 )
 
 type LoggerConfig struct {
-	LogMode                 string `yaml:"logMode" json:"logMode"`
+	LogMode                 string `yaml:"logMode" json:"logMode" default:"info"`
 	IgnoreErrRecordNotFound *bool  `yaml:"ignoreErrRecordNotFound" json:"ignoreErrRecordNotFound"`
-	SlowThresholdMS         int    `yaml:"slowThresholdMS" json:"slowThresholdMS"`
+	// 慢查询的阈值，为0时不记录慢查询，配置文件中缺省时为200ms
+	SlowThreshold config.Duration `yaml:"slowThreshold" json:"slowThreshold" default:"200ms"`
+	// Deprecated: 使用slowThreshold，设置时优先于slowThreshold，小于0时不记录慢查询
+	SlowThresholdMS int `yaml:"slowThresholdMS" json:"slowThresholdMS"`
 	// zap log config
 	// Encoding     string   `json:"encoding" yaml:"encoding"`
 	// OutputPaths  []string `json:"outputPaths" yaml:"outputPaths"`
@@ -18,15 +22,15 @@ type LoggerConfig struct {
 	// DurationUnit string   `yaml:"durationUnit" json:"durationUnit"`
 }
 
-func getDefaultLoggerConfig() *LoggerConfig {
-	var ignored bool = false
-	return &LoggerConfig{
-		LogMode:                 "info",
-		IgnoreErrRecordNotFound: &ignored,
-		SlowThresholdMS:         200,
-		// Encoding:                "json",
-		// TimeFormat:              "rfc3339utc",
+// cfg为nil时返回默认配置。从配置文件读取的配置已经应用过默认值，
+// 不能再次设置，否则明确写出的slowThreshold: 0会被改回默认值
+func withDefaults(cfg *LoggerConfig) *LoggerConfig {
+	if cfg != nil {
+		return cfg
 	}
+	c := &LoggerConfig{}
+	_ = config.SetDefaults(c)
+	return c
 }
 
 func getLogLevel(logMod string) logger.LogLevel {
@@ -53,9 +57,12 @@ func isIgnoreErrRecordNotFound(b *bool) bool {
 	return *b
 }
 
-func getSlowThreshold(n int) time.Duration {
-	if n <= 0 {
-		n = 200
+func getSlowThreshold(cfg *LoggerConfig) time.Duration {
+	if cfg.SlowThresholdMS < 0 {
+		return 0
 	}
-	return time.Duration(n) * time.Millisecond
+	if cfg.SlowThresholdMS > 0 {
+		return time.Duration(cfg.SlowThresholdMS) * time.Millisecond
+	}
+	return cfg.SlowThreshold.Std()
 }
@@ -24,9 +24,7 @@ type zapLogger struct {
 }
 
 func NewZapLogger(cfg *LoggerConfig) *zapLogger {
-	if cfg == nil {
-		cfg = getDefaultLoggerConfig()
-	}
+	cfg = withDefaults(cfg)
 
 	zapConfig := zap.NewProductionConfig()
 	zapConfig.DisableCaller = true
@@ -87,7 +85,7 @@ func NewZapLogger(cfg *LoggerConfig) *zapLogger {
 		zlogger:                   l.Sugar(),
 		LogLevel:                  getLogLevel(cfg.LogMode),
 		IgnoreRecordNotFoundError: isIgnoreErrRecordNotFound(cfg.IgnoreErrRecordNotFound),
-		SlowThreshold:             getSlowThreshold(cfg.SlowThresholdMS),
+		SlowThreshold:             getSlowThreshold(cfg),
 	}
 	return zlogger
 }
@@ -19,13 +19,11 @@ type zeroLogger struct {
 }
 
 func NewZeroLogger(cfg *LoggerConfig) *zeroLogger {
-	if cfg == nil {
-		cfg = getDefaultLoggerConfig()
-	}
+	cfg = withDefaults(cfg)
 	return &zeroLogger{
 		logger:                    log.With().Str("ltag", "gormStarter").Logger(),
 		LogLevel:                  getLogLevel(cfg.LogMode),
-		SlowThreshold:             getSlowThreshold(cfg.SlowThresholdMS),
+		SlowThreshold:             getSlowThreshold(cfg),
 		IgnoreRecordNotFoundError: isIgnoreErrRecordNotFound(cfg.IgnoreErrRecordNotFound),
 	}
 }
@@ -1,11 +1,14 @@
 package gormstarter
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/rs/zerolog/log"
+	"github.com/why2go/gostarter/boot"
 	"github.com/why2go/gostarter/config"
 	gormLogger "github.com/why2go/gostarter/gormstarter/logger"
 	"gorm.io/driver/mysql"
@@ -16,23 +19,80 @@ import (
 )
 
 var (
-	dataSourceMap map[string]*gorm.DB
+	dataSourceMap = make(map[string]*gorm.DB)
 	logger        = log.With().Str("ltag", "gormStarter").Logger()
+
+	initMu      sync.Mutex
+	initialized bool
 )
 
-func init() {
+// 读取gorm配置项并连接所有数据源，重复调用时直接返回。
+// 导入gormstarter/autoinit包，可以在init阶段自动完成初始化
+func Init(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initialized {
+		return nil
+	}
 	var cfg gormConfig
 	err := config.GetConfig(&cfg)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("load gorm config failed")
-		return
+		return fmt.Errorf("load gorm config failed: %w", err)
 	}
 	if len(cfg) == 0 {
-		logger.Fatal().Msg("no data source config found")
+		return errors.New("no data source config found")
 	}
-	dataSourceMap = make(map[string]*gorm.DB)
 	for srcName, srcCfg := range cfg {
-		dataSourceMap[srcName] = newGormDB(srcName, srcCfg)
+		if srcCfg == nil {
+			return &config.ConfigError{Name: cfg.ConfigName(), Err: fmt.Errorf("data source %s is empty", srcName)}
+		}
+	}
+	dbs := make(map[string]*gorm.DB, len(cfg))
+	for srcName, srcCfg := range cfg {
+		logger.Info().Msgf("trying to connect to data source: %s...", srcName)
+		var db *gorm.DB
+		err := srcCfg.Retry.Do(ctx, "gorm:"+srcName, func(ctx context.Context) (err error) {
+			db, err = New(ctx, srcCfg)
+			return
+		})
+		if err != nil {
+			// 关闭已经建立的连接
+			for _, opened := range dbs {
+				if sqlDb, err := opened.DB(); err == nil {
+					sqlDb.Close()
+				}
+			}
+			return &boot.ConnectionError{Target: "gorm:" + srcName, Err: err}
+		}
+		logger.Info().Msgf("successfully connect to data source: %s!", srcName)
+		dbs[srcName] = db
+	}
+	for srcName, db := range dbs {
+		dataSourceMap[srcName] = db
+	}
+	initialized = true
+	return nil
+}
+
+// 已连接的数据源的健康检查函数，key为"gorm:数据源名"
+func HealthCheckers() map[string]boot.HealthChecker {
+	initMu.Lock()
+	defer initMu.Unlock()
+	checkers := make(map[string]boot.HealthChecker, len(dataSourceMap))
+	for srcName, db := range dataSourceMap {
+		checkers["gorm:"+srcName] = pingDB(db)
+	}
+	return checkers
+}
+
+// 使用ping来检查数据源连接是否可用
+func pingDB(db *gorm.DB) boot.HealthChecker {
+	return func(ctx context.Context) error {
+		sqlDb, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDb.PingContext(ctx)
 	}
 }
 
@@ -49,16 +109,32 @@ func GetDbBySourceName(srcName string) (*gorm.DB, error) {
 	}
 }
 
-type gormConfig map[string]*dataSourceConfig
+type gormConfig map[string]*DataSourceConfig
 
-type dataSourceConfig struct {
-	DBType          string                   `yaml:"dbType" json:"dbType"`
+// 单个数据源的配置
+type DataSourceConfig struct {
 	DSN             string                   `yaml:"dsn" json:"dsn"`
-	ConnMaxIdleTime string                   `yaml:"connMaxIdleTime" json:"connMaxIdleTime"`
-	ConnMaxLifetime string                   `yaml:"connMaxLifeTime" json:"connMaxLifeTime"`
-	MaxIdleConns    *int                     `yaml:"maxIdleConns" json:"maxIdleConns"`
-	MaxOpenConns    *int                     `yaml:"maxOpenConns" json:"maxOpenConns"`
+	DBType          string                   `yaml:"dbType" json:"dbType" default:"mysql"`
+	ConnMaxIdleTime config.Duration          `yaml:"connMaxIdleTime" json:"connMaxIdleTime" default:"30m"`
+	ConnMaxLifetime config.Duration          `yaml:"connMaxLifeTime" json:"connMaxLifeTime"` // 默认没有限制
+	MaxIdleConns    *int                     `yaml:"maxIdleConns" json:"maxIdleConns" default:"1"`
+	MaxOpenConns    *int                     `yaml:"maxOpenConns" json:"maxOpenConns" default:"10"`
 	Logger          *gormLogger.LoggerConfig `yaml:"logger" json:"logger"`
+	Retry           *boot.RetryPolicy        `yaml:"retry" json:"retry"` // 连接失败时的重试策略，默认不重试
+}
+
+// 校验数据库类型，除sqlite之外都需要配置dsn
+func (cfg *DataSourceConfig) Validate() error {
+	switch strings.ToLower(cfg.DBType) {
+	case "", dbTypeMysql, dbTypePostgres, dbTypeSqlServer:
+		if len(strings.TrimSpace(cfg.DSN)) == 0 {
+			return errors.New("dsn is required")
+		}
+	case dbTypeSqlite:
+	default:
+		return fmt.Errorf("unsupport db type: %s", cfg.DBType)
+	}
+	return nil
 }
 
 func (cfg *gormConfig) ConfigName() string {
@@ -72,19 +148,17 @@ const (
 	dbTypeSqlServer = "sqlserver"
 )
 
-func newGormDB(srcName string, cfg *dataSourceConfig) *gorm.DB {
+// 根据配置创建一个数据源连接，不会注册到gormstarter中。
+// 配置按原样使用，代码中构造的配置需要先调用config.SetDefaults设置默认值，未设置的连接池参数使用database/sql的默认值
+func New(ctx context.Context, cfg *DataSourceConfig) (*gorm.DB, error) {
 	if cfg == nil {
-		log.Fatal().Msg("gorm config is nil")
-		return nil
-	}
-	if len(cfg.DBType) == 0 {
-		cfg.DBType = dbTypeMysql
+		return nil, errors.New("gorm config is nil")
 	}
-	logger.Info().Msgf("trying to connect to data source: %s...", srcName)
+	dbType := cfg.DBType
 	// 设置数据库类型
 	var dialector gorm.Dialector
-	switch strings.ToLower(cfg.DBType) {
-	case dbTypeMysql:
+	switch strings.ToLower(dbType) {
+	case dbTypeMysql, "":
 		dialector = mysql.Open(cfg.DSN)
 	case dbTypePostgres:
 		dialector = postgres.Open(cfg.DSN)
@@ -93,49 +167,27 @@ func newGormDB(srcName string, cfg *dataSourceConfig) *gorm.DB {
 	case dbTypeSqlServer:
 		dialector = sqlserver.Open(cfg.DSN)
 	default:
-		logger.Fatal().Msgf("unsupport db type: %s", cfg.DBType)
-		return nil
+		return nil, fmt.Errorf("unsupport db type: %s", dbType)
 	}
 	db, err := gorm.Open(dialector, &gorm.Config{Logger: gormLogger.NewZeroLogger(cfg.Logger)})
 	if err != nil {
-		logger.Fatal().Err(err).Msgf("can't connect to %s server", cfg.DBType)
-		return nil
+		return nil, fmt.Errorf("can't connect to %s server: %w", dbType, err)
 	}
 	sqlDb, err := db.DB()
 	if err != nil {
-		logger.Fatal().Err(err).Msgf("can't connect to %s server", cfg.DBType)
-		return nil
-	}
-	connMaxIdleTime := 30 * time.Minute // 默认30min
-	var connMaxLifetime time.Duration   // 默认没有限制
-	maxOpenConns := 10                  // 默认10个活跃连接
-	maxIdleConns := 1                   // 默认1个空闲连接
-	if len(cfg.ConnMaxIdleTime) != 0 {
-		d, err := time.ParseDuration(cfg.ConnMaxIdleTime)
-		if err != nil {
-			logger.Fatal().Err(err).Msgf("invalid duration expression: %s", cfg.ConnMaxIdleTime)
-			return nil
-		}
-		connMaxIdleTime = d
-	}
-	if len(cfg.ConnMaxLifetime) != 0 {
-		d, err := time.ParseDuration(cfg.ConnMaxLifetime)
-		if err != nil {
-			logger.Fatal().Err(err).Msgf("invalid duration expression: %s", cfg.ConnMaxLifetime)
-			return nil
-		}
-		connMaxLifetime = d
+		return nil, fmt.Errorf("can't connect to %s server: %w", dbType, err)
 	}
+	sqlDb.SetConnMaxIdleTime(cfg.ConnMaxIdleTime.Std())
+	sqlDb.SetConnMaxLifetime(cfg.ConnMaxLifetime.Std())
 	if cfg.MaxOpenConns != nil {
-		maxOpenConns = *cfg.MaxOpenConns
+		sqlDb.SetMaxOpenConns(*cfg.MaxOpenConns)
 	}
 	if cfg.MaxIdleConns != nil {
-		maxIdleConns = *cfg.MaxIdleConns
-	}
-	sqlDb.SetConnMaxIdleTime(connMaxIdleTime)
-	sqlDb.SetConnMaxLifetime(connMaxLifetime)
-	sqlDb.SetMaxOpenConns(maxOpenConns)
-	sqlDb.SetMaxIdleConns(maxIdleConns)
-	logger.Info().Msgf("successfully connect to data source: %s!", srcName)
-	return db
+		sqlDb.SetMaxIdleConns(*cfg.MaxIdleConns)
+	}
+	if err = sqlDb.PingContext(ctx); err != nil {
+		sqlDb.Close()
+		return nil, fmt.Errorf("can't connect to %s server: %w", dbType, err)
+	}
+	return db, nil
 }
@@ -0,0 +1,35 @@
+package grpcstarter
+
+import (
+	"context"
+
+	"github.com/why2go/gostarter/boot"
+)
+
+const componentName = "grpc"
+
+type grpcComponent struct{}
+
+// 返回grpc server对应的boot组件，启动和清理自动配对：
+//
+//	boot.Use(grpcstarter.Component())
+func Component() boot.Component {
+	return &grpcComponent{}
+}
+
+func (*grpcComponent) Name() string {
+	return componentName
+}
+
+// 在数据源之后启动，在数据源之前清理
+func (*grpcComponent) After() []string {
+	return []string{"gorm", "mongo", "redis", "kafka"}
+}
+
+func (*grpcComponent) Start(ctx context.Context) error {
+	return grpcSrvInstance.start()
+}
+
+func (*grpcComponent) Stop(ctx context.Context) error {
+	return grpcSrvInstance.stop(ctx)
+}
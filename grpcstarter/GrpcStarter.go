@@ -1,10 +1,14 @@
 package grpcstarter
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
+	"github.com/why2go/gostarter/boot"
 	config "github.com/why2go/gostarter/config"
 	"github.com/why2go/gostarter/grpcstarter/interceptor"
 	_ "github.com/why2go/gostarter/zerologstarter"
@@ -14,26 +18,37 @@ import (
 )
 
 var (
-	logger = log.With().Str("ltag", "grpcStarter").Logger()
+	logger  = log.With().Str("ltag", "grpcStarter").Logger()
+	initErr error // 加载配置时出现的错误，启动grpc server时返回
 )
 
 func init() {
 	cfg := &grpcConf{}
 	err := config.GetConfig(cfg)
 	if err != nil && err != config.ErrNoConfigItemFound {
-		logger.Fatal().Err(err).Msg("load grpc server config failed")
-		return
+		// 使用默认配置创建server，保证注册service的代码可以正常执行，错误在启动时返回
+		logger.Err(err).Msg("load grpc server config failed")
+		initErr = fmt.Errorf("load grpc server config failed: %w", err)
+		cfg = &grpcConf{}
+		_ = config.SetDefaults(cfg)
 	}
 
 	grpcSrvInstance = newGrpcServer(cfg)
+	boot.AddHealthChecker("grpc", func(context.Context) error {
+		if !grpcSrvInstance.serving.Load() {
+			return errors.New("grpc server is not serving")
+		}
+		return nil
+	})
 }
 
 type grpcConf struct {
-	Host            string `yaml:"host" json:"host"`
-	Port            uint16 `yaml:"port" json:"port"`
-	ConnTimeoutMS   uint32 `yaml:"connTimeoutMS" json:"connTimeoutMS"`
-	WriteBufferSize uint32 `yaml:"writeBufferSize" json:"writeBufferSize"`
-	ReadBufferSize  uint32 `yaml:"readBufferSize" json:"readBufferSize"`
+	Host            string          `yaml:"host" json:"host"`
+	Port            uint16          `yaml:"port" json:"port" default:"8081"`
+	ConnTimeout     config.Duration `yaml:"connTimeout" json:"connTimeout"`
+	ConnTimeoutMS   uint32          `yaml:"connTimeoutMS" json:"connTimeoutMS"` // Deprecated: 使用connTimeout
+	WriteBufferSize config.ByteSize `yaml:"writeBufferSize" json:"writeBufferSize"`
+	ReadBufferSize  config.ByteSize `yaml:"readBufferSize" json:"readBufferSize"`
 	Logger          struct {
 		SkipMethods []string `yaml:"skipMethods" json:"skipMethods"`
 	} `yaml:"logger" json:"logger"`
@@ -45,10 +60,6 @@ func (cfg *grpcConf) ConfigName() string {
 	return "grpc"
 }
 
-const (
-	defaultPort = uint16(8081)
-)
-
 var (
 	grpcSrvInstance *grpcServer
 )
@@ -58,24 +69,23 @@ type grpcServer struct {
 	port       uint16
 	opts       []grpc.ServerOption
 	grpcServer *grpc.Server
+	serving    atomic.Bool
 }
 
 func newGrpcServer(cfg *grpcConf) *grpcServer {
-	srv := &grpcServer{host: cfg.Host}
-	if cfg.Port == 0 {
-		srv.port = defaultPort
-	} else {
-		srv.port = cfg.Port
-	}
+	srv := &grpcServer{host: cfg.Host, port: cfg.Port}
 	if cfg.WriteBufferSize != 0 {
-		srv.opts = append(srv.opts, grpc.WriteBufferSize(int(cfg.WriteBufferSize)))
+		srv.opts = append(srv.opts, grpc.WriteBufferSize(cfg.WriteBufferSize.Int()))
 	}
 	if cfg.ReadBufferSize != 0 {
-		srv.opts = append(srv.opts, grpc.ReadBufferSize(int(cfg.ReadBufferSize)))
+		srv.opts = append(srv.opts, grpc.ReadBufferSize(cfg.ReadBufferSize.Int()))
 	}
-	if cfg.ConnTimeoutMS != 0 {
-		srv.opts = append(srv.opts,
-			grpc.ConnectionTimeout(time.Duration(cfg.ConnTimeoutMS)*time.Millisecond))
+	connTimeout := cfg.ConnTimeout.Std()
+	if connTimeout == 0 && cfg.ConnTimeoutMS != 0 {
+		connTimeout = time.Duration(cfg.ConnTimeoutMS) * time.Millisecond
+	}
+	if connTimeout != 0 {
+		srv.opts = append(srv.opts, grpc.ConnectionTimeout(connTimeout))
 	}
 	usi := defaultChainedInterceptors(interceptor.NewUnaryConf(cfg.Logger.SkipMethods))
 	srv.opts = append(srv.opts,
@@ -93,27 +103,57 @@ func GetGrpcServer() *grpc.Server {
 	return grpcSrvInstance.grpcServer
 }
 
-func StartGrpcServer() {
-	addr := fmt.Sprintf("%s:%d", grpcSrvInstance.host, grpcSrvInstance.port)
+func StartGrpcServer() error {
+	if err := grpcSrvInstance.start(); err != nil {
+		logger.Err(err).Msg("grpc listen error")
+		return err
+	}
+	return nil
+}
+
+func StopGrpcServer() {
+	grpcSrvInstance.stop(context.Background())
+}
+
+func (srv *grpcServer) start() error {
+	if initErr != nil {
+		return initErr
+	}
+	addr := fmt.Sprintf("%s:%d", srv.host, srv.port)
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("grpc listen error")
-		return
+		return err
 	}
+	srv.serving.Store(true)
 	// 不要阻塞主线程
 	go func() {
-		err = grpcSrvInstance.grpcServer.Serve(lis)
+		defer srv.serving.Store(false)
+		err := srv.grpcServer.Serve(lis)
 		if err != nil {
-			logger.Fatal().Err(err).Msg("grpc serve error")
-			return
+			logger.Err(err).Msg("grpc serve error")
 		}
 	}()
+	return nil
 }
 
-func StopGrpcServer() {
+// 优雅关闭，ctx结束时还没有关闭完成则强制关闭
+func (srv *grpcServer) stop(ctx context.Context) error {
 	logger.Info().Msg("shutting down grpc server...")
-	grpcSrvInstance.grpcServer.GracefulStop()
+	done := make(chan struct{})
+	go func() {
+		srv.grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.grpcServer.Stop()
+		<-done
+		logger.Warn().Msg("grpc server is forcibly closed")
+		return ctx.Err()
+	}
 	logger.Info().Msg("grpc server is closed")
+	return nil
 }
 
 // var (
@@ -3,9 +3,9 @@
 //	grpc:
 //	 host: "localhost"
 //	 port: 8081
-//	 connTimeoutMS: 5000
-//	 writeBufferSize: 4096 # bytes
-//	 readBufferSize: 4096 # bytes
+//	 connTimeout: 5s # 旧的connTimeoutMS仍然有效
+//	 writeBufferSize: 32KiB # 也可以写作字节数，例如4096
+//	 readBufferSize: 32KiB
 //	 interceptors:
 //	   - incoming
 //	   - outgoing
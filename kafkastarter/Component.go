@@ -0,0 +1,49 @@
+package kafkastarter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/why2go/gostarter/boot"
+)
+
+const componentName = "kafka"
+
+type kafkaComponent struct{}
+
+// 返回所有kafka客户端对应的boot组件，启动时调用Init创建客户端，清理时关闭客户端，启动后向应用提供各个连接的健康检查：
+//
+//	boot.Use(kafkastarter.Component())
+func Component() boot.Component {
+	return &kafkaComponent{}
+}
+
+func (*kafkaComponent) Name() string {
+	return componentName
+}
+
+func (*kafkaComponent) Start(ctx context.Context) error {
+	return Init(ctx)
+}
+
+func (*kafkaComponent) HealthCheckers() map[string]boot.HealthChecker {
+	return HealthCheckers()
+}
+
+// 关闭所有客户端并清空，之后再次启动时重新创建
+func (*kafkaComponent) Stop(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	var errs []error
+	for name, c := range clients {
+		delete(clients, name)
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close kafka %s failed: %w", name, err))
+			continue
+		}
+		logger.Info().Msgf("kafka client %s is closed", name)
+	}
+	initialized = false
+	return errors.Join(errs...)
+}
@@ -1,7 +1,133 @@
 package kafkastarter
 
-// 如何创建生产者
-// 如何创建消费者
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
+	"github.com/why2go/gostarter/boot"
+	"github.com/why2go/gostarter/config"
+
+	"github.com/Shopify/sarama"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	logger  = log.With().Str("ltag", "kafkaStarter").Logger()
+	clients = make(map[string]sarama.Client)
+
+	initMu      sync.Mutex
+	initialized bool
+)
+
+var (
+	ErrClientNotFound = errors.New("kafka client not found")
+)
+
+// 读取kafka配置项并创建所有客户端，没有kafka配置项时不做任何事情，重复调用时直接返回。
+// 导入kafkastarter/autoinit包，可以在init阶段自动完成初始化
+func Init(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initialized {
+		return nil
+	}
+	cfg := &kafkaConfig{}
+	err := config.GetConfig(cfg)
+	if err != nil && err != config.ErrNoConfigItemFound {
+		return fmt.Errorf("load kafka config failed: %w", err)
+	}
+	for k, cc := range cfg.Clients {
+		if cc == nil {
+			return &config.ConfigError{Name: cfg.ConfigName(), Err: fmt.Errorf("client %s is empty", k)}
+		}
+	}
+	connected := make(map[string]sarama.Client, len(cfg.Clients))
+	for k, cc := range cfg.Clients {
+		k = strings.TrimSpace(k)
+		logger.Info().Msgf("try to connect to kafka: %s", k)
+		var c sarama.Client
+		err := cc.Retry.Do(ctx, "kafka:"+k, func(context.Context) (err error) {
+			c, err = New(cc)
+			return
+		})
+		if err != nil {
+			for _, opened := range connected {
+				opened.Close()
+			}
+			return &boot.ConnectionError{Target: "kafka:" + k, Err: err}
+		}
+		logger.Info().Msgf("successfully connect to kafka: %s", k)
+		connected[k] = c
+	}
+	for k, c := range connected {
+		clients[k] = c
+	}
+	initialized = true
+	return nil
+}
+
+// 已创建的kafka客户端的健康检查函数，key为"kafka:客户端名"
+func HealthCheckers() map[string]boot.HealthChecker {
+	initMu.Lock()
+	defer initMu.Unlock()
+	checkers := make(map[string]boot.HealthChecker, len(clients))
+	for k, c := range clients {
+		c := c
+		checkers["kafka:"+k] = func(context.Context) error {
+			return c.RefreshMetadata()
+		}
+	}
+	return checkers
+}
+
+// 获取已经建立连接的kafka客户端，可以用它创建生产者和消费者，例如：
+//
+//	client, _ := kafkastarter.GetKafkaClient("kafka_1")
+//	producer, err := sarama.NewSyncProducerFromClient(client)
+//	consumerGroup, err := sarama.NewConsumerGroupFromClient("group_1", client)
+func GetKafkaClient(which string) (sarama.Client, error) {
+	if c, ok := clients[which]; ok {
+		return c, nil
+	}
+	return nil, ErrClientNotFound
+}
+
+// 根据配置创建一个kafka客户端，不会注册到kafkastarter中
+func New(cfg *ClientConfig) (sarama.Client, error) {
+	if cfg == nil || len(cfg.Brokers) == 0 {
+		return nil, errors.New("no kafka brokers configured")
+	}
+	saramaCfg := sarama.NewConfig()
+	if len(cfg.ClientId) != 0 {
+		saramaCfg.ClientID = cfg.ClientId
+	}
+	if len(cfg.Version) != 0 {
+		v, err := sarama.ParseKafkaVersion(cfg.Version)
+		if err != nil {
+			return nil, err
+		}
+		saramaCfg.Version = v
+	}
+	return sarama.NewClient(cfg.Brokers, saramaCfg)
+}
+
+// 配置项
 type kafkaConfig struct {
+	Clients map[string]*ClientConfig `yaml:"clients" json:"clients"`
+}
+
+func (cfg *kafkaConfig) ConfigName() string {
+	return "kafka"
+}
+
+// 单个kafka客户端的配置
+type ClientConfig struct {
+	Brokers  []string `yaml:"brokers" json:"brokers" validate:"required"`
+	Version  string   `yaml:"version" json:"version"`
+	ClientId string   `yaml:"clientId" json:"clientId"`
+	// 连接失败时的重试策略，默认不重试
+	Retry *boot.RetryPolicy `yaml:"retry" json:"retry"`
 }
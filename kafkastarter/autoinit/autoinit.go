@@ -0,0 +1,21 @@
+// 导入此包，在init阶段读取配置并创建所有kafka客户端，失败时退出进程：
+//
+//	import _ "github.com/why2go/gostarter/kafkastarter/autoinit"
+package autoinit
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/why2go/gostarter/boot"
+	"github.com/why2go/gostarter/kafkastarter"
+)
+
+func init() {
+	if err := kafkastarter.Init(context.Background()); err != nil {
+		log.Fatal().Str("ltag", "kafkaStarter").Err(err).Msg("init kafka failed")
+	}
+	for name, checker := range kafkastarter.HealthCheckers() {
+		boot.AddHealthChecker(name, checker)
+	}
+}
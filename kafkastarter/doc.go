@@ -0,0 +1,20 @@
+// 支持多个kafka集群的配置，配置格式如下：
+/*
+	kafka:
+	  clients:
+	    kafka_1:
+	      brokers: ["localhost:9092", "localhost:9093"]
+	      version: 2.8.0
+	      clientId: echo
+	      retry: # 可选，连接失败时的重试策略，详见boot.RetryPolicy
+	        maxAttempts: 5
+	        deadline: 30s
+*/
+//
+// 导入本包不会读取配置或建立连接，需要通过以下任一方式显式初始化：
+//   - boot.Use(kafkastarter.Component())：在应用启动时初始化，清理时关闭连接
+//   - kafkastarter.Init(ctx)：手动初始化
+//   - import _ "github.com/why2go/gostarter/kafkastarter/autoinit"：在init阶段初始化，失败时退出进程
+//
+// 初始化之后，使用kafkastarter.GetKafkaClient("kafka_1")获取客户端。
+package kafkastarter
@@ -0,0 +1,49 @@
+package mongostarter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/why2go/gostarter/boot"
+)
+
+const componentName = "mongo"
+
+type mongoComponent struct{}
+
+// 返回所有mongo客户端对应的boot组件，启动时调用Init连接客户端，清理时断开连接，启动后向应用提供各个连接的健康检查：
+//
+//	boot.Use(mongostarter.Component())
+func Component() boot.Component {
+	return &mongoComponent{}
+}
+
+func (*mongoComponent) Name() string {
+	return componentName
+}
+
+func (*mongoComponent) Start(ctx context.Context) error {
+	return Init(ctx)
+}
+
+func (*mongoComponent) HealthCheckers() map[string]boot.HealthChecker {
+	return HealthCheckers()
+}
+
+// 断开所有客户端并清空，之后再次启动时重新连接
+func (*mongoComponent) Stop(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	var errs []error
+	for name, c := range clients {
+		delete(clients, name)
+		if err := c.Disconnect(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("disconnect mongo %s failed: %w", name, err))
+			continue
+		}
+		logger.Info().Msgf("mongo client %s is disconnected", name)
+	}
+	initialized = false
+	return errors.Join(errs...)
+}
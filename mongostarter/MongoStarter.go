@@ -3,9 +3,12 @@ package mongostarter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/why2go/gostarter/boot"
 	config "github.com/why2go/gostarter/config"
 
 	"github.com/rs/zerolog/log"
@@ -17,27 +20,71 @@ import (
 var (
 	clients = make(map[string]*mongo.Client)
 	logger  = log.With().Str("ltag", "mongoStarter").Logger()
+
+	initMu      sync.Mutex
+	initialized bool
 )
 
 var (
 	ErrMongoClientNotFound = errors.New("mongo client not found")
 )
 
-func init() {
+// 读取mongo配置项并连接所有客户端，重复调用时直接返回。
+// 导入mongostarter/autoinit包，可以在init阶段自动完成初始化
+func Init(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initialized {
+		return nil
+	}
 	cfg := &mongoConf{}
 	err := config.GetConfig(cfg)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("load mongo config failed")
-		return
+		return fmt.Errorf("load mongo config failed: %w", err)
 	}
 	for k, cc := range cfg.ClientsConfig {
-		c, err := newMongoClient(k, strings.TrimSpace(cc.ConnectionString))
-		if err != nil {
-			logger.Fatal().Err(err).Msgf("failed to connect to mongo: %s", k)
+		if cc == nil {
+			return &config.ConfigError{Name: cfg.ConfigName(), Err: fmt.Errorf("client %s is empty", k)}
+		}
+	}
+	connected := make(map[string]*mongo.Client, len(cfg.ClientsConfig))
+	for k, cc := range cfg.ClientsConfig {
+		k = strings.TrimSpace(k)
+		logger.Info().Msgf("try to connect to mongo server: %s", k)
+		var c *mongo.Client
+		err := cc.Retry.Do(ctx, "mongo:"+k, func(ctx context.Context) (err error) {
+			c, err = New(ctx, cc)
 			return
+		})
+		if err != nil {
+			// 断开已经建立的连接
+			for _, opened := range connected {
+				opened.Disconnect(ctx)
+			}
+			return &boot.ConnectionError{Target: "mongo:" + k, Err: err}
 		}
-		clients[strings.TrimSpace(k)] = c
+		logger.Info().Msgf("successfully connect to mongo server: %s", k)
+		connected[k] = c
 	}
+	for k, c := range connected {
+		clients[k] = c
+	}
+	initialized = true
+	return nil
+}
+
+// 已连接的mongo客户端的健康检查函数，key为"mongo:客户端名"
+func HealthCheckers() map[string]boot.HealthChecker {
+	initMu.Lock()
+	defer initMu.Unlock()
+	checkers := make(map[string]boot.HealthChecker, len(clients))
+	for k, c := range clients {
+		c := c
+		checkers["mongo:"+k] = func(ctx context.Context) error {
+			return c.Ping(ctx, readpref.Primary())
+		}
+	}
+	return checkers
 }
 
 func GetMongoClient(which string) (*mongo.Client, error) {
@@ -47,44 +94,48 @@ func GetMongoClient(which string) (*mongo.Client, error) {
 	return nil, ErrMongoClientNotFound
 }
 
-func newMongoClient(clientName, connStr string) (*mongo.Client, error) {
-	logger.Info().Msgf("try to connect to mongo server: %s", clientName)
-	opts := options.Client().ApplyURI(connStr)
+// 根据配置创建一个mongo客户端并完成连接，不会注册到mongostarter中
+func New(ctx context.Context, cfg *ClientConfig) (*mongo.Client, error) {
+	if cfg == nil {
+		return nil, errors.New("mongo client config is nil")
+	}
+	opts := options.Client().ApplyURI(strings.TrimSpace(cfg.ConnectionString))
 	client, err := mongo.NewClient(opts)
 	if err != nil {
 		return nil, err
 	}
 	connTimeout := 2 * time.Second
-	if *opts.ConnectTimeout != 0 {
+	if opts.ConnectTimeout != nil && *opts.ConnectTimeout != 0 {
 		connTimeout = *opts.ConnectTimeout
 	}
-	ctx, cf := context.WithTimeout(context.Background(), connTimeout)
-	err = client.Connect(ctx)
+	connCtx, cf := context.WithTimeout(ctx, connTimeout)
+	err = client.Connect(connCtx)
 	cf()
 	if err != nil {
 		return nil, err
 	}
 	// 使用ping来测试连接是否成功
-	pingCtx, pingCf := context.WithTimeout(context.Background(), connTimeout)
+	pingCtx, pingCf := context.WithTimeout(ctx, connTimeout)
 	defer pingCf()
 	err = client.Ping(pingCtx, readpref.Primary())
 	if err != nil {
+		client.Disconnect(ctx)
 		return nil, err
 	}
-	logger.Info().Msgf("successfully connect to mongo server: %s", clientName)
-
 	return client, nil
 }
 
 // 配置项
 type mongoConf struct {
-	ClientsConfig map[string]*clientConfig `yaml:"clients" json:"clients" toml:"clients"`
+	ClientsConfig map[string]*ClientConfig `yaml:"clients" json:"clients" toml:"clients"`
 }
 
 func (cfg *mongoConf) ConfigName() string {
 	return "mongo"
 }
 
-type clientConfig struct {
-	ConnectionString string `yaml:"connectionString" json:"connectionString"`
+// 单个mongo客户端的配置
+type ClientConfig struct {
+	ConnectionString string            `yaml:"connectionString" json:"connectionString" validate:"required"`
+	Retry            *boot.RetryPolicy `yaml:"retry" json:"retry"` // 连接失败时的重试策略，默认不重试
 }
@@ -0,0 +1,21 @@
+// 导入此包，在init阶段读取配置并连接所有mongo客户端，失败时退出进程：
+//
+//	import _ "github.com/why2go/gostarter/mongostarter/autoinit"
+package autoinit
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/why2go/gostarter/boot"
+	"github.com/why2go/gostarter/mongostarter"
+)
+
+func init() {
+	if err := mongostarter.Init(context.Background()); err != nil {
+		log.Fatal().Str("ltag", "mongoStarter").Err(err).Msg("init mongo failed")
+	}
+	for name, checker := range mongostarter.HealthCheckers() {
+		boot.AddHealthChecker(name, checker)
+	}
+}
@@ -1,12 +1,23 @@
 // 支持多个数据源配置，配置格式如下：
 /*
 	mongo:
-	  server_1:
-	    connectionString:
-	  server_2:
-	    connectionString:
+	  clients:
+	    server_1:
+	      connectionString:
+	      retry: # 可选，连接失败时的重试策略，详见boot.RetryPolicy
+	        maxAttempts: 5
+	        deadline: 30s
+	    server_2:
+	      connectionString:
 
 connectionString的形式请参考:
   https://www.mongodb.com/docs/manual/reference/connection-string/
 */
+//
+// 导入本包不会读取配置或建立连接，需要通过以下任一方式显式初始化：
+//   - boot.Use(mongostarter.Component())：在应用启动时初始化，清理时关闭连接
+//   - mongostarter.Init(ctx)：手动初始化
+//   - import _ "github.com/why2go/gostarter/mongostarter/autoinit"：在init阶段初始化，失败时退出进程
+//
+// 初始化之后，使用mongostarter.GetMongoClient("server_1")获取客户端。
 package mongostarter
@@ -0,0 +1,21 @@
+// 导入此包，在init阶段读取配置并连接所有redis客户端，失败时退出进程：
+//
+//	import _ "github.com/why2go/gostarter/redisstarter/goredisstarter/autoinit"
+package autoinit
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/why2go/gostarter/boot"
+	"github.com/why2go/gostarter/redisstarter/goredisstarter"
+)
+
+func init() {
+	if err := goredisstarter.Init(context.Background()); err != nil {
+		log.Fatal().Str("ltag", "redisStarter").Err(err).Msg("init redis failed")
+	}
+	for name, checker := range goredisstarter.HealthCheckers() {
+		boot.AddHealthChecker(name, checker)
+	}
+}
@@ -0,0 +1,54 @@
+package goredisstarter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/why2go/gostarter/boot"
+)
+
+const componentName = "redis"
+
+type redisComponent struct{}
+
+// 返回所有redis客户端对应的boot组件，启动时调用Init连接客户端，清理时关闭连接，启动后向应用提供各个连接的健康检查：
+//
+//	boot.Use(goredisstarter.Component())
+func Component() boot.Component {
+	return &redisComponent{}
+}
+
+func (*redisComponent) Name() string {
+	return componentName
+}
+
+func (*redisComponent) Start(ctx context.Context) error {
+	return Init(ctx)
+}
+
+func (*redisComponent) HealthCheckers() map[string]boot.HealthChecker {
+	return HealthCheckers()
+}
+
+// 关闭所有客户端并清空，之后再次启动时重新连接
+func (*redisComponent) Stop(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	var errs []error
+	for name, c := range clients {
+		delete(clients, name)
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close redis %s failed: %w", name, err))
+		}
+	}
+	for name, cc := range clusterClients {
+		delete(clusterClients, name)
+		if err := cc.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close redis cluster %s failed: %w", name, err))
+		}
+	}
+	initialized = false
+	logger.Info().Msg("redis clients are closed")
+	return errors.Join(errs...)
+}
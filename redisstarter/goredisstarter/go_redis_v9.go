@@ -3,9 +3,12 @@ package goredisstarter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/why2go/gostarter/boot"
 	"github.com/why2go/gostarter/config"
 
 	"github.com/redis/go-redis/v9"
@@ -16,6 +19,9 @@ var (
 	logger         = log.With().Str("ltag", "redisStarter").Logger()
 	clients        = make(map[string]*redis.Client)
 	clusterClients = make(map[string]*redis.ClusterClient)
+
+	initMu      sync.Mutex
+	initialized bool
 )
 
 var (
@@ -25,68 +31,155 @@ var (
 // 支持server client， cluster client
 
 type redisConfig struct {
-	Clients        map[string]*clientConfig        `yaml:"clients" json:"clients"`
-	ClusterClients map[string]*clusterClientConfig `yaml:"cluster_clients" json:"cluster_clients"`
+	Clients        map[string]*ClientConfig        `yaml:"clients" json:"clients"`
+	ClusterClients map[string]*ClusterClientConfig `yaml:"cluster_clients" json:"cluster_clients"`
 }
 
 func (redisConfig) ConfigName() string {
 	return "go_redis"
 }
 
-type clientConfig struct {
-	ConnUrl string `yaml:"conn_url" json:"conn_url"`
+// 单个redis客户端的配置
+type ClientConfig struct {
+	ConnUrl string            `yaml:"conn_url" json:"conn_url" validate:"required"`
+	Retry   *boot.RetryPolicy `yaml:"retry" json:"retry"` // 连接失败时的重试策略，默认不重试
 }
 
-type clusterClientConfig struct {
-	ConnUrl string `yaml:"conn_url" json:"conn_url"`
+// 单个redis cluster客户端的配置
+type ClusterClientConfig struct {
+	ConnUrl string            `yaml:"conn_url" json:"conn_url" validate:"required"`
+	Retry   *boot.RetryPolicy `yaml:"retry" json:"retry"` // 连接失败时的重试策略，默认不重试
 }
 
-func init() {
-	var err error
+// 读取redis配置项并连接所有客户端，重复调用时直接返回。
+// 导入goredisstarter/autoinit包，可以在init阶段自动完成初始化
+func Init(ctx context.Context) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initialized {
+		return nil
+	}
 	cfg := &redisConfig{}
-	err = config.GetConfig(cfg)
+	err := config.GetConfig(cfg)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("load redis config failed")
-		return
+		return fmt.Errorf("load redis config failed: %w", err)
 	}
-	// redis server client
 	for k, v := range cfg.Clients {
-		opts, err := redis.ParseURL(strings.TrimSpace(v.ConnUrl))
-		if err != nil {
-			logger.Fatal().Err(err).Msgf("malformed redis conn url: %s", v.ConnUrl)
-			return
+		if v == nil {
+			return &config.ConfigError{Name: cfg.ConfigName(), Err: fmt.Errorf("client %s is empty", k)}
 		}
-		client := redis.NewClient(opts)
-		ctx, cf := context.WithTimeout(context.Background(), time.Second)
-		sc := client.Ping(ctx)
-		cf()
-		if sc.Err() != nil {
-			logger.Fatal().Err(sc.Err()).Msgf("failed to ping redis: %s", k)
-			return
+	}
+	for k, v := range cfg.ClusterClients {
+		if v == nil {
+			return &config.ConfigError{Name: cfg.ConfigName(), Err: fmt.Errorf("cluster client %s is empty", k)}
+		}
+	}
+	connected := make(map[string]*redis.Client, len(cfg.Clients))
+	connectedClusters := make(map[string]*redis.ClusterClient, len(cfg.ClusterClients))
+	closeConnected := func() {
+		for _, c := range connected {
+			c.Close()
+		}
+		for _, cc := range connectedClusters {
+			cc.Close()
 		}
+	}
+	// redis server client
+	for k, v := range cfg.Clients {
 		k = strings.TrimSpace(k)
-		clients[k] = client
+		var client *redis.Client
+		err := v.Retry.Do(ctx, "redis:"+k, func(ctx context.Context) (err error) {
+			client, err = New(ctx, v)
+			return
+		})
+		if err != nil {
+			closeConnected()
+			return &boot.ConnectionError{Target: "redis:" + k, Err: err}
+		}
+		connected[k] = client
 		logger.Info().Msgf("successfully connected to redis: %s", k)
 	}
 	// redis cluster client
 	for k, v := range cfg.ClusterClients {
-		opts, err := redis.ParseClusterURL(strings.TrimSpace(v.ConnUrl))
-		if err != nil {
-			logger.Fatal().Err(err).Msgf("malformed redis cluster conn url: %s", v.ConnUrl)
-			return
-		}
-		cc := redis.NewClusterClient(opts)
-		ctx, cf := context.WithTimeout(context.Background(), time.Second)
-		sc := cc.Ping(ctx)
-		cf()
-		if sc.Err() != nil {
-			logger.Fatal().Err(sc.Err()).Msgf("failed to ping redis cluster: %s", k)
+		k = strings.TrimSpace(k)
+		var cc *redis.ClusterClient
+		err := v.Retry.Do(ctx, "redis_cluster:"+k, func(ctx context.Context) (err error) {
+			cc, err = NewCluster(ctx, v)
 			return
+		})
+		if err != nil {
+			closeConnected()
+			return &boot.ConnectionError{Target: "redis_cluster:" + k, Err: err}
 		}
-		k = strings.TrimSpace(k)
-		clusterClients[k] = cc
+		connectedClusters[k] = cc
 		logger.Info().Msgf("successfully connected to redis cluster: %s", k)
 	}
+	for k, client := range connected {
+		clients[k] = client
+	}
+	for k, cc := range connectedClusters {
+		clusterClients[k] = cc
+	}
+	initialized = true
+	return nil
+}
+
+// 已连接的redis客户端的健康检查函数，key为"redis:客户端名"或"redis_cluster:客户端名"
+func HealthCheckers() map[string]boot.HealthChecker {
+	initMu.Lock()
+	defer initMu.Unlock()
+	checkers := make(map[string]boot.HealthChecker, len(clients)+len(clusterClients))
+	for k, client := range clients {
+		client := client
+		checkers["redis:"+k] = func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		}
+	}
+	for k, cc := range clusterClients {
+		cc := cc
+		checkers["redis_cluster:"+k] = func(ctx context.Context) error {
+			return cc.Ping(ctx).Err()
+		}
+	}
+	return checkers
+}
+
+// 根据配置创建一个redis客户端，并使用ping检查连接，不会注册到goredisstarter中
+func New(ctx context.Context, cfg *ClientConfig) (*redis.Client, error) {
+	if cfg == nil {
+		return nil, errors.New("redis client config is nil")
+	}
+	opts, err := redis.ParseURL(strings.TrimSpace(cfg.ConnUrl))
+	if err != nil {
+		return nil, fmt.Errorf("malformed redis conn url: %w", err)
+	}
+	client := redis.NewClient(opts)
+	pingCtx, cf := context.WithTimeout(ctx, time.Second)
+	defer cf()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// 根据配置创建一个redis cluster客户端，并使用ping检查连接，不会注册到goredisstarter中
+func NewCluster(ctx context.Context, cfg *ClusterClientConfig) (*redis.ClusterClient, error) {
+	if cfg == nil {
+		return nil, errors.New("redis cluster client config is nil")
+	}
+	opts, err := redis.ParseClusterURL(strings.TrimSpace(cfg.ConnUrl))
+	if err != nil {
+		return nil, fmt.Errorf("malformed redis cluster conn url: %w", err)
+	}
+	cc := redis.NewClusterClient(opts)
+	pingCtx, cf := context.WithTimeout(ctx, time.Second)
+	defer cf()
+	if err := cc.Ping(pingCtx).Err(); err != nil {
+		cc.Close()
+		return nil, err
+	}
+	return cc, nil
 }
 
 // 获取已经建立连接的redis客户端
@@ -0,0 +1,302 @@
+package goredisstarter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 基于redis的分布式锁，可用于多副本部署时的互斥执行和选主：
+//   - 通过SET NX PX加锁，锁的值为持有者的随机标识，只有持有者能续期和释放
+//   - 每次加锁成功时递增对应的fencing token，下游可以用它拒绝过期持有者的写入
+//   - 持有期间在后台自动续期，续期失败时通过Lease.Done()通知持有者
+//
+// 锁和token的key分别为"lock:{name}"和"lock:{name}:fence"，在集群中位于同一个slot
+
+var (
+	ErrLockNotAcquired = errors.New("lock not acquired")
+	ErrLockLost        = errors.New("lock lost")
+)
+
+var (
+	defaultLockTTL           = 30 * time.Second
+	defaultLockRetryInterval = 200 * time.Millisecond
+	minLockTTL               = 3 * time.Millisecond // PX至少为1ms，续期间隔ttl/3也不能为0
+
+)
+
+var (
+	// 加锁成功时返回新的fencing token，否则返回0
+	acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`)
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+type MutexOption func(*Mutex)
+
+// 锁的有效期，持有期间每隔ttl/3续期一次，默认30s，最小3ms
+func WithLockTTL(ttl time.Duration) MutexOption {
+	return func(m *Mutex) {
+		if ttl > 0 && ttl < minLockTTL {
+			ttl = minLockTTL
+		}
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// Lock等待锁时的重试间隔，默认200ms
+func WithLockRetryInterval(d time.Duration) MutexOption {
+	return func(m *Mutex) {
+		m.retryInterval = d
+	}
+}
+
+// 分布式锁，可以在多个goroutine中复用
+type Mutex struct {
+	client        redis.UniversalClient
+	name          string
+	key           string
+	fenceKey      string
+	ttl           time.Duration
+	retryInterval time.Duration
+}
+
+// 使用指定的redis客户端创建一个分布式锁，client可以是*redis.Client或*redis.ClusterClient
+func NewMutex(client redis.UniversalClient, name string, opts ...MutexOption) *Mutex {
+	m := &Mutex{
+		client:        client,
+		name:          name,
+		key:           "lock:{" + name + "}",
+		fenceKey:      "lock:{" + name + "}:fence",
+		ttl:           defaultLockTTL,
+		retryInterval: defaultLockRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// 使用已经建立连接的redis客户端或者redis cluster客户端创建一个分布式锁
+func GetMutex(which string, name string, opts ...MutexOption) (*Mutex, error) {
+	if c, ok := clients[which]; ok {
+		return NewMutex(c, name, opts...), nil
+	}
+	if c, ok := clusterClients[which]; ok {
+		return NewMutex(c, name, opts...), nil
+	}
+	return nil, ErrClientNotFound
+}
+
+// 尝试加锁一次，锁被其他持有者占用时返回ErrLockNotAcquired
+func (m *Mutex) TryLock(ctx context.Context) (*Lease, error) {
+	owner, err := newOwnerId()
+	if err != nil {
+		return nil, err
+	}
+	// 以发出请求的时间估算锁的过期时间，宁早勿晚
+	expireAt := time.Now().Add(m.ttl)
+	token, err := acquireScript.Run(ctx, m.client, []string{m.key, m.fenceKey}, owner, m.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("acquire lock %s failed: %w", m.name, err)
+	}
+	if token == 0 {
+		return nil, ErrLockNotAcquired
+	}
+	return newLease(m, owner, token, expireAt), nil
+}
+
+// 加锁，锁被占用时每隔retryInterval重试一次，直到成功或者ctx结束
+func (m *Mutex) Lock(ctx context.Context) (*Lease, error) {
+	for {
+		lease, err := m.TryLock(ctx)
+		if err == nil || !errors.Is(err, ErrLockNotAcquired) {
+			return lease, err
+		}
+		timer := time.NewTimer(m.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// 将定时任务包装为只在获得锁的副本上执行，没有获得锁时直接跳过，例如：
+//
+//	mutex, _ := goredisstarter.GetMutex("redis_1", "cleanup", goredisstarter.WithLockTTL(10*time.Second))
+//	cronstarter.Register("cleanup", "@every 1m", mutex.Wrap(cleanup))
+//
+// fn的ctx在锁丢失时被取消。fn返回后不会主动释放锁，而是停止续期等待锁过期，
+// 以免其他副本在同一时刻稍晚触发时重复执行，因此ttl应当小于任务的执行间隔
+func (m *Mutex) Wrap(fn func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		lease, err := m.TryLock(ctx)
+		if errors.Is(err, ErrLockNotAcquired) {
+			logger.Debug().Str("lock", m.name).Msg("lock is held by another replica, skip")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer lease.stopRenewal()
+		runCtx, cf := context.WithCancel(ctx)
+		defer cf()
+		go func() {
+			select {
+			case <-lease.Done():
+				cf()
+			case <-runCtx.Done():
+			}
+		}()
+		return fn(runCtx)
+	}
+}
+
+// 一次成功的加锁
+type Lease struct {
+	mutex *Mutex
+	owner string
+	token int64
+
+	lost     chan struct{} // 续期失败时关闭
+	stop     chan struct{} // 停止续期时关闭
+	stopOnce sync.Once
+	lostOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func newLease(m *Mutex, owner string, token int64, expireAt time.Time) *Lease {
+	l := &Lease{
+		mutex: m,
+		owner: owner,
+		token: token,
+		lost:  make(chan struct{}),
+		stop:  make(chan struct{}),
+	}
+	l.wg.Add(1)
+	go l.keepAlive(expireAt)
+	return l
+}
+
+// 本次加锁的fencing token，随加锁次数单调递增
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// 锁丢失（续期失败或者已过期）时关闭，用于选主时感知失去领导权
+func (l *Lease) Done() <-chan struct{} {
+	return l.lost
+}
+
+// 释放锁，锁已经被其他持有者获得时返回ErrLockLost
+func (l *Lease) Release(ctx context.Context) error {
+	l.stopRenewal()
+	n, err := releaseScript.Run(ctx, l.mutex.client, []string{l.mutex.key}, l.owner).Int64()
+	if err != nil {
+		return fmt.Errorf("release lock %s failed: %w", l.mutex.name, err)
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l *Lease) stopRenewal() {
+	l.stopOnce.Do(func() { close(l.stop) })
+	l.wg.Wait()
+}
+
+func (l *Lease) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+// 一次续期的结果
+type renewal struct {
+	sentAt time.Time
+	n      int64
+	err    error
+}
+
+func (l *Lease) keepAlive(expireAt time.Time) {
+	defer l.wg.Done()
+	ttl := l.mutex.ttl
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	// 续期失败时，锁在expireAt过期，届时立即通知持有者，不等续期请求返回。
+	// 客户端没有开启ContextTimeoutEnabled时，请求不受ctx的时限约束，因此续期在单独的goroutine中执行
+	expiry := time.NewTimer(time.Until(expireAt))
+	defer expiry.Stop()
+	results := make(chan renewal, 1)
+	renewing := false
+	// 退出时取消正在进行的续期请求
+	renewCtx, cancelRenew := context.WithCancel(context.Background())
+	defer cancelRenew()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-expiry.C:
+			logger.Warn().Str("lock", l.mutex.name).Msg("renew lock failed, lock expired")
+			l.markLost()
+			return
+		case <-ticker.C:
+			if renewing {
+				continue
+			}
+			renewing = true
+			go func() {
+				ctx, cf := context.WithTimeout(renewCtx, ttl/3)
+				defer cf()
+				r := renewal{sentAt: time.Now()}
+				r.n, r.err = renewScript.Run(ctx, l.mutex.client, []string{l.mutex.key}, l.owner, ttl.Milliseconds()).Int64()
+				results <- r
+			}()
+		case r := <-results:
+			renewing = false
+			switch {
+			case r.err == nil && r.n == 1:
+				expireAt = r.sentAt.Add(ttl)
+				if !expiry.Stop() {
+					<-expiry.C
+				}
+				expiry.Reset(time.Until(expireAt))
+			case r.err == nil:
+				logger.Warn().Str("lock", l.mutex.name).Msg("lock is held by another owner")
+				l.markLost()
+				return
+			default:
+				logger.Warn().Err(r.err).Str("lock", l.mutex.name).Msg("renew lock failed, will retry")
+			}
+		}
+	}
+}
+
+func newOwnerId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
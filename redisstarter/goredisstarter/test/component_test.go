@@ -0,0 +1,47 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/boot"
+	"github.com/why2go/gostarter/redisstarter/goredisstarter"
+)
+
+// 配置目录在init阶段确定，需要在子进程中使用临时目录
+func TestComponentRestart(t *testing.T) {
+	if os.Getenv("REDIS_COMPONENT_CHILD") != "" {
+		ctx := context.Background()
+		app, err := boot.New()
+		assert.Nil(t, err)
+		assert.Nil(t, app.Use(goredisstarter.Component()))
+
+		for i := 0; i < 2; i++ {
+			assert.Nil(t, app.Start(ctx))
+			client, err := goredisstarter.GetRedisClient("cache")
+			assert.Nil(t, err)
+			assert.Nil(t, client.Ping(ctx).Err())
+			assert.Equal(t, boot.StatusUp, app.Readiness(ctx).Checks["redis:cache"].Status)
+
+			assert.Nil(t, app.Stop(ctx))
+			_, err = goredisstarter.GetRedisClient("cache")
+			assert.ErrorIs(t, err, goredisstarter.ErrClientNotFound)
+			assert.Empty(t, app.Health(ctx).Checks)
+		}
+		return
+	}
+	mr := miniredis.RunT(t)
+	dir := t.TempDir()
+	yaml := fmt.Sprintf("go_redis:\n  clients:\n    cache:\n      conn_url: redis://%s/0\n", mr.Addr())
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(yaml), 0644))
+	cmd := exec.Command(os.Args[0], "-test.run=^TestComponentRestart$")
+	cmd.Env = append(os.Environ(), "REDIS_COMPONENT_CHILD=1", "CONFIG_DIR="+dir)
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/why2go/gostarter/redisstarter/goredisstarter"
+)
+
+func newClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+// 可以让redis失去响应的连接：挂起之后写入的命令被丢弃，读取一直等到超时
+type hangingConn struct {
+	net.Conn
+	hang *atomic.Bool
+}
+
+func (c *hangingConn) Write(b []byte) (int, error) {
+	if c.hang.Load() {
+		return len(b), nil
+	}
+	return c.Conn.Write(b)
+}
+
+func newHangingClient(t *testing.T, hang *atomic.Bool) *redis.Client {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &hangingConn{Conn: conn, hang: hang}, nil
+		},
+	})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestMutex(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("FencingToken", func(t *testing.T) {
+		_, client := newClient(t)
+		m := goredisstarter.NewMutex(client, "fence")
+		lease, err := m.TryLock(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), lease.Token())
+
+		_, err = m.TryLock(ctx)
+		assert.ErrorIs(t, err, goredisstarter.ErrLockNotAcquired)
+
+		assert.Nil(t, lease.Release(ctx))
+		lease, err = m.TryLock(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), lease.Token())
+		assert.Nil(t, lease.Release(ctx))
+	})
+
+	t.Run("Renewal", func(t *testing.T) {
+		mr, client := newClient(t)
+		m := goredisstarter.NewMutex(client, "renew", goredisstarter.WithLockTTL(30*time.Millisecond))
+		lease, err := m.TryLock(ctx)
+		assert.Nil(t, err)
+		// miniredis不会自动让key过期，通过剩余时间是否被重置来判断续期
+		mr.FastForward(25 * time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return mr.TTL("lock:{renew}") > 10*time.Millisecond
+		}, time.Second, 5*time.Millisecond)
+		select {
+		case <-lease.Done():
+			t.Fatal("lease lost while renewing")
+		default:
+		}
+		assert.Nil(t, lease.Release(ctx))
+	})
+
+	t.Run("Lost", func(t *testing.T) {
+		mr, client := newClient(t)
+		m := goredisstarter.NewMutex(client, "lost", goredisstarter.WithLockTTL(30*time.Millisecond))
+		lease, err := m.TryLock(ctx)
+		assert.Nil(t, err)
+		assert.Nil(t, mr.Set("lock:{lost}", "another owner"))
+		select {
+		case <-lease.Done():
+		case <-time.After(time.Second):
+			t.Fatal("lost lease not detected")
+		}
+		assert.ErrorIs(t, lease.Release(ctx), goredisstarter.ErrLockLost)
+	})
+
+	t.Run("ExpireWhileUnreachable", func(t *testing.T) {
+		var hang atomic.Bool
+		ttl := 300 * time.Millisecond
+		m := goredisstarter.NewMutex(newHangingClient(t, &hang), "unreachable", goredisstarter.WithLockTTL(ttl))
+		begin := time.Now()
+		lease, err := m.TryLock(ctx)
+		assert.Nil(t, err)
+		hang.Store(true)
+		select {
+		case <-lease.Done():
+			// 锁最晚在加锁请求发出ttl之后过期，Done不能晚于这个时间太多
+			assert.Less(t, time.Since(begin), ttl+30*time.Millisecond)
+		case <-time.After(2 * ttl):
+			t.Fatal("expired lease not detected")
+		}
+	})
+
+	t.Run("Wrap", func(t *testing.T) {
+		mr, client := newClient(t)
+		m := goredisstarter.NewMutex(client, "wrap")
+		runs := 0
+		job := m.Wrap(func(context.Context) error {
+			runs++
+			return nil
+		})
+
+		lease, err := m.TryLock(ctx)
+		assert.Nil(t, err)
+		assert.Nil(t, job(ctx))
+		assert.Equal(t, 0, runs)
+		assert.Nil(t, lease.Release(ctx))
+
+		assert.Nil(t, job(ctx))
+		assert.Equal(t, 1, runs)
+		// 执行之后不释放锁，等待锁过期
+		assert.True(t, mr.Exists("lock:{wrap}"))
+		assert.Nil(t, job(ctx))
+		assert.Equal(t, 1, runs)
+
+		errBoom := errors.New("boom")
+		mr.Del("lock:{wrap}")
+		assert.ErrorIs(t, m.Wrap(func(context.Context) error { return errBoom })(ctx), errBoom)
+	})
+
+	t.Run("WrapCancelOnLost", func(t *testing.T) {
+		mr, client := newClient(t)
+		m := goredisstarter.NewMutex(client, "cancel", goredisstarter.WithLockTTL(30*time.Millisecond))
+		err := m.Wrap(func(ctx context.Context) error {
+			assert.Nil(t, mr.Set("lock:{cancel}", "another owner"))
+			<-ctx.Done()
+			return ctx.Err()
+		})(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("TinyTTL", func(t *testing.T) {
+		mr, client := newClient(t)
+		m := goredisstarter.NewMutex(client, "tiny", goredisstarter.WithLockTTL(time.Microsecond))
+		lease, err := m.TryLock(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 3*time.Millisecond, mr.TTL("lock:{tiny}"))
+		assert.Nil(t, lease.Release(ctx))
+	})
+}
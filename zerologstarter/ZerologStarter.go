@@ -16,19 +16,26 @@ func init() {
 	cfg := &zerologConf{}
 	err = config.GetConfig(cfg)
 	if err != nil {
-		if err == config.ErrNoConfigItemFound {
-			cfg.GlobalLevel = zerolog.LevelInfoValue
-		} else {
-			log.Fatal().Err(err).Msg("init zerolog failed")
-			return
+		// 配置有误时同样使用默认配置，错误会在读取其他配置项时再次返回
+		if err != config.ErrNoConfigItemFound {
+			log.Error().Err(err).Msg("init zerolog failed, use default config")
 		}
+		cfg = &zerologConf{GlobalLevel: zerolog.LevelInfoValue}
 	}
 	// 设置GolbalLevel
-	level, err := zerolog.ParseLevel(cfg.GlobalLevel)
-	if err != nil {
-		level = zerolog.InfoLevel
+	setGlobalLevel(cfg.GlobalLevel)
+	// 开启watch之后，修改配置文件中的globalLevel无需重启即可生效
+	if cfg.Watch {
+		if _, err := config.Watch(&zerologConf{}, func(old, new config.Configurable) {
+			prev, curr := old.(*zerologConf), new.(*zerologConf)
+			if prev.GlobalLevel != curr.GlobalLevel {
+				setGlobalLevel(curr.GlobalLevel)
+				log.Info().Str("level", zerolog.GlobalLevel().String()).Msg("zerolog global level changed")
+			}
+		}); err != nil {
+			log.Error().Err(err).Msg("watch zerolog config failed")
+		}
 	}
-	zerolog.SetGlobalLevel(level)
 	zerolog.TimeFieldFormat = time.RFC3339Nano
 	zerolog.TimestampFunc = func() time.Time { return time.Now().UTC() }
 	zerolog.MessageFieldName = "msg"
@@ -44,7 +51,7 @@ func init() {
 
 	if cfg.EnableRotation {
 		if cfg.Logger == nil {
-			log.Fatal().Msgf("init zerolog failed, rotation config can't be nil")
+			log.Error().Msgf("init zerolog failed, rotation config can't be nil")
 			return
 		}
 		w := lumberjack.Logger{
@@ -62,9 +69,18 @@ type zerologConf struct {
 	GlobalLevel        string `yaml:"globalLevel" json:"globalLevel"`
 	DurationFieldUnit  string `yaml:"durationFieldUnit" json:"durationFieldUnit"`
 	EnableRotation     bool   `yaml:"enableRotation" json:"enableRotation"`
+	Watch              bool   `yaml:"watch" json:"watch"` // 监听配置文件的变化，默认关闭
 	*lumberjack.Logger `yaml:"rotationConfig" json:"rotationConfig"`
 }
 
 func (cfg *zerologConf) ConfigName() string {
 	return "zerolog"
 }
+
+func setGlobalLevel(s string) {
+	level, err := zerolog.ParseLevel(s)
+	if err != nil {
+		level = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(level)
+}